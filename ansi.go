@@ -0,0 +1,274 @@
+package logview
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"strconv"
+	"strings"
+)
+
+// AnsiMode defines how ANSI escape sequences in event messages are handled
+type AnsiMode int
+
+const (
+	// AnsiRaw leaves escape sequences in the event messages as is
+	AnsiRaw AnsiMode = iota
+	// AnsiStrip removes escape sequences from the event messages
+	AnsiStrip
+	// AnsiColors removes escape sequences from the event messages and displays the text with the colors and attributes
+	// set by SGR sequences
+	AnsiColors
+)
+
+// ansiStyle is a text style set by SGR sequences. tcell.ColorDefault means the color is not set
+type ansiStyle struct {
+	fg    tcell.Color
+	bg    tcell.Color
+	attrs tcell.AttrMask
+}
+
+// ansiSpan is a part of the event message styled with SGR sequences
+type ansiSpan struct {
+	start int
+	end   int
+	style ansiStyle
+}
+
+// SetAnsiMode sets how ANSI escape sequences in event messages are handled. Changing the mode updates all the events.
+//
+// In AnsiColors mode colors set by SGR sequences take precedence over the event level colors, while the parts of
+// the message matched by the highlight pattern (see SetHighlightPattern) are highlighted with the pattern styles.
+//
+// Default is AnsiRaw
+func (lv *LogView) SetAnsiMode(mode AnsiMode) {
+	lv.Lock()
+	defer lv.Unlock()
+
+	if lv.ansiMode == mode {
+		return
+	}
+	lv.ansiMode = mode
+	lv.reparseMessages()
+}
+
+// GetAnsiMode returns the current mode of ANSI escape sequences handling
+func (lv *LogView) GetAnsiMode() AnsiMode {
+	lv.RLock()
+	defer lv.RUnlock()
+
+	return lv.ansiMode
+}
+
+// *******************************
+// internal implementation details
+
+func (s ansiStyle) apply(style tcell.Style) tcell.Style {
+	if s.fg != tcell.ColorDefault {
+		style = style.Foreground(s.fg)
+	}
+	if s.bg != tcell.ColorDefault {
+		style = style.Background(s.bg)
+	}
+	if s.attrs != tcell.AttrNone {
+		style = addAttributes(style, s.attrs)
+	}
+	return style
+}
+
+func (s ansiStyle) isDefault() bool {
+	return s.fg == tcell.ColorDefault && s.bg == tcell.ColorDefault && s.attrs == tcell.AttrNone
+}
+
+// parseAnsi removes escape sequences from the message and expands tabs. If withStyles is true, returns the spans of
+// the message text styled with SGR sequences
+func parseAnsi(message string, tabWidth int, withStyles bool) ([]rune, []ansiSpan) {
+	if !strings.ContainsRune(message, '\x1b') {
+		return expandTabs(message, tabWidth), nil
+	}
+	runes := []rune(message)
+	text := make([]rune, 0, len(runes))
+	var spans []ansiSpan
+	current := ansiStyle{fg: tcell.ColorDefault, bg: tcell.ColorDefault}
+	spanStart := 0
+	column := 0
+	for pos := 0; pos < len(runes); pos++ {
+		r := runes[pos]
+		switch {
+		case r == '\x1b':
+			var params string
+			var final rune
+			params, final, pos = readEscapeSequence(runes, pos)
+			if final != 'm' || !withStyles {
+				continue
+			}
+			next := current.applySGR(params)
+			if next != current {
+				if len(text) > spanStart && !current.isDefault() {
+					spans = append(spans, ansiSpan{start: spanStart, end: len(text), style: current})
+				}
+				spanStart = len(text)
+				current = next
+			}
+		case r == '\t' && tabWidth > 0:
+			spaces := tabWidth - column%tabWidth
+			for i := 0; i < spaces; i++ {
+				text = append(text, ' ')
+			}
+			column += spaces
+		case r == '\n':
+			text = append(text, r)
+			column = 0
+		default:
+			text = append(text, r)
+			column += cellWidth(r)
+		}
+	}
+	if len(text) > spanStart && !current.isDefault() {
+		spans = append(spans, ansiSpan{start: spanStart, end: len(text), style: current})
+	}
+	return text, spans
+}
+
+// readEscapeSequence reads escape sequence starting at pos. Returns parameters and the final character of CSI
+// sequence and the position of the last character of the sequence
+func readEscapeSequence(runes []rune, pos int) (string, rune, int) {
+	if pos+1 >= len(runes) {
+		return "", 0, pos
+	}
+	switch runes[pos+1] {
+	case '[': // CSI: parameters and intermediate bytes followed by a final byte in range 0x40-0x7e
+		for end := pos + 2; end < len(runes); end++ {
+			if runes[end] >= 0x40 && runes[end] <= 0x7e {
+				return string(runes[pos+2 : end]), runes[end], end
+			}
+		}
+		return "", 0, len(runes) - 1
+	case ']': // OSC: terminated by BEL or ESC \
+		for end := pos + 2; end < len(runes); end++ {
+			if runes[end] == '\a' {
+				return "", 0, end
+			}
+			if runes[end] == '\x1b' && end+1 < len(runes) && runes[end+1] == '\\' {
+				return "", 0, end + 1
+			}
+		}
+		return "", 0, len(runes) - 1
+	default: // two-character sequence
+		return "", 0, pos + 1
+	}
+}
+
+var sgrAttributes = map[int]tcell.AttrMask{
+	1: tcell.AttrBold,
+	2: tcell.AttrDim,
+	3: tcell.AttrItalic,
+	4: tcell.AttrUnderline,
+	5: tcell.AttrBlink,
+	7: tcell.AttrReverse,
+	9: tcell.AttrStrikeThrough,
+}
+
+var sgrResetAttributes = map[int]tcell.AttrMask{
+	22: tcell.AttrBold | tcell.AttrDim,
+	23: tcell.AttrItalic,
+	24: tcell.AttrUnderline,
+	25: tcell.AttrBlink,
+	27: tcell.AttrReverse,
+	29: tcell.AttrStrikeThrough,
+}
+
+// applySGR returns the style modified by SGR sequence parameters
+func (s ansiStyle) applySGR(params string) ansiStyle {
+	codes := strings.Split(params, ";")
+	for i := 0; i < len(codes); i++ {
+		code, err := strconv.Atoi(codes[i])
+		if err != nil {
+			if codes[i] != "" {
+				continue
+			}
+			code = 0 // empty parameter is the same as 0
+		}
+		switch {
+		case code == 0:
+			s = ansiStyle{fg: tcell.ColorDefault, bg: tcell.ColorDefault}
+		case sgrAttributes[code] != 0:
+			s.attrs |= sgrAttributes[code]
+		case sgrResetAttributes[code] != 0:
+			s.attrs &^= sgrResetAttributes[code]
+		case code >= 30 && code <= 37:
+			s.fg = tcell.PaletteColor(code - 30)
+		case code >= 90 && code <= 97:
+			s.fg = tcell.PaletteColor(code - 90 + 8)
+		case code >= 40 && code <= 47:
+			s.bg = tcell.PaletteColor(code - 40)
+		case code >= 100 && code <= 107:
+			s.bg = tcell.PaletteColor(code - 100 + 8)
+		case code == 39:
+			s.fg = tcell.ColorDefault
+		case code == 49:
+			s.bg = tcell.ColorDefault
+		case code == 38 || code == 48:
+			var color tcell.Color
+			color, i = parseExtendedColor(codes, i+1)
+			if code == 38 {
+				s.fg = color
+			} else {
+				s.bg = color
+			}
+		}
+	}
+	return s
+}
+
+// parseExtendedColor parses 256 color (5;n) or true color (2;r;g;b) parameters starting at index i.
+// Returns the color and the index of the last parameter used
+func parseExtendedColor(codes []string, i int) (tcell.Color, int) {
+	if i >= len(codes) {
+		return tcell.ColorDefault, i
+	}
+	switch codes[i] {
+	case "5":
+		if i+1 < len(codes) {
+			if n, err := strconv.Atoi(codes[i+1]); err == nil && n >= 0 && n < 256 {
+				return tcell.PaletteColor(n), i + 1
+			}
+		}
+		return tcell.ColorDefault, i + 1
+	case "2":
+		values := make([]int32, 0, 3)
+		for j := i + 1; j < len(codes) && j <= i+3; j++ {
+			n, err := strconv.Atoi(codes[j])
+			if err != nil {
+				n = 0
+			}
+			values = append(values, int32(n))
+		}
+		if len(values) < 3 {
+			return tcell.ColorDefault, len(codes) - 1
+		}
+		return tcell.NewRGBColor(values[0], values[1], values[2]), i + 3
+	}
+	return tcell.ColorDefault, i
+}
+
+// plainSpans returns spans for the part of the message that is not highlighted by the highlight pattern,
+// applying ANSI styles to the default style
+func plainSpans(ansi []ansiSpan, start int, end int, style tcell.Style) []styledSpan {
+	spans := make([]styledSpan, 0, 1)
+	pos := start
+	for _, span := range ansi {
+		if span.end <= pos || span.start >= end {
+			continue
+		}
+		if span.start > pos {
+			spans = append(spans, styledSpan{start: pos, end: span.start, style: style})
+			pos = span.start
+		}
+		spanEnd := minInt(span.end, end)
+		spans = append(spans, styledSpan{start: pos, end: spanEnd, style: span.style.apply(style)})
+		pos = spanEnd
+	}
+	if pos < end {
+		spans = append(spans, styledSpan{start: pos, end: end, style: style})
+	}
+	return spans
+}
@@ -0,0 +1,70 @@
+package logview
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"testing"
+)
+
+func TestParseAnsi(t *testing.T) {
+	text, spans := parseAnsi("\x1b[1;31mERROR\x1b[0m: \x1b]0;title\x07disk\tfull \x1b[38;5;208morange\x1b[39m", 4, true)
+	if string(text) != "ERROR: disk full orange" {
+		t.Errorf("Escape sequences should be removed: %q", string(text))
+	}
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 spans, got %+v", spans)
+	}
+	if spans[0].start != 0 || spans[0].end != 5 || spans[0].style.fg != tcell.ColorMaroon || spans[0].style.attrs != tcell.AttrBold {
+		t.Errorf("Invalid first span: %+v", spans[0])
+	}
+	if spans[1].start != 17 || spans[1].end != 23 || spans[1].style.fg != tcell.PaletteColor(208) {
+		t.Errorf("Invalid second span: %+v", spans[1])
+	}
+
+	text, spans = parseAnsi("\x1b[32mok\x1b[0m", 4, false)
+	if string(text) != "ok" || spans != nil {
+		t.Errorf("Strip mode should not produce spans: %q %+v", string(text), spans)
+	}
+
+	_, spans = parseAnsi("\x1b[48;2;10;20;30mtrue color", 4, true)
+	if len(spans) != 1 || spans[0].style.bg != tcell.NewRGBColor(10, 20, 30) {
+		t.Errorf("Invalid true color span: %+v", spans)
+	}
+}
+
+func TestLogView_AnsiColors(t *testing.T) {
+	lv := NewLogView()
+	lv.SetAnsiMode(AnsiColors)
+	lv.SetHighlightPattern(`(?P<blue>\d+)`)
+	lv.AppendEvent(NewLogEvent("1", "\x1b[31mfailed 42 times\x1b[0m, giving up"))
+
+	e := lv.firstEvent
+	if string(e.Runes) != "failed 42 times, giving up" {
+		t.Fatalf("Escape sequences should be removed: %q", string(e.Runes))
+	}
+	styleAt := func(pos int) tcell.Style {
+		for _, span := range e.styleSpans {
+			if pos >= span.start && pos < span.end {
+				return span.style
+			}
+		}
+		return tcell.StyleDefault
+	}
+	if fg, _, _ := styleAt(0).Decompose(); fg != tcell.ColorMaroon {
+		t.Errorf("ANSI color should be applied, got %v", fg)
+	}
+	if fg, _, _ := styleAt(7).Decompose(); fg != tcell.ColorBlue {
+		t.Errorf("Highlight pattern should take precedence over ANSI color, got %v", fg)
+	}
+	if styleAt(20) != lv.defaultStyle {
+		t.Errorf("Text after reset should use default style")
+	}
+
+	lv.SetAnsiMode(AnsiRaw)
+	if string(lv.firstEvent.Runes) != "\x1b[31mfailed 42 times\x1b[0m, giving up" {
+		t.Errorf("Changing mode should update existing events: %q", string(lv.firstEvent.Runes))
+	}
+	lv.AppendEvent(NewLogEvent("2", "\x1b[31mraw"))
+	if string(lv.lastEvent.Runes) != "\x1b[31mraw" {
+		t.Errorf("Raw mode should keep escape sequences: %q", string(lv.lastEvent.Runes))
+	}
+}
@@ -0,0 +1,327 @@
+package logview
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"sort"
+)
+
+// bookmarkMarker is displayed in the gutter next to the bookmarked events
+const bookmarkMarker = '●'
+
+// markAction is the action waiting for the name of the mark after the mark key was pressed
+type markAction int
+
+const (
+	markNone markAction = iota
+	markSet
+	markJump
+)
+
+// ToggleBookmark adds a bookmark to the current event or removes it. Returns true if the event is bookmarked now.
+//
+// Bookmarks are kept when events are re-highlighted or re-wrapped and are removed when the event is evicted from the
+// log view. Bookmarked events are marked in the gutter displayed to the left of the header while there are any
+// bookmarks or named marks
+func (lv *LogView) ToggleBookmark() bool {
+	lv.Lock()
+	defer lv.Unlock()
+
+	return lv.toggleBookmark()
+}
+
+// ClearBookmarks removes all the bookmarks and named marks
+func (lv *LogView) ClearBookmarks() {
+	lv.Lock()
+	defer lv.Unlock()
+
+	for _, seq := range lv.bookmarks {
+		if event := lv.index.bySeq(seq); event != nil {
+			setBookmarked(event, false)
+		}
+	}
+	lv.bookmarks = nil
+	lv.marks = nil
+}
+
+// NextBookmark selects the next bookmarked event after the current one and scrolls it into view. Wraps around to the
+// first bookmark when the last one is reached. Events hidden by the filter are skipped.
+//
+// Returns false if there are no bookmarked events.
+func (lv *LogView) NextBookmark() bool {
+	defer lv.fireOnCurrentChange(lv.current)
+	lv.Lock()
+	defer lv.Unlock()
+
+	return lv.gotoBookmark(true)
+}
+
+// PrevBookmark selects the previous bookmarked event before the current one and scrolls it into view. Wraps around to
+// the last bookmark when the first one is reached. Events hidden by the filter are skipped.
+//
+// Returns false if there are no bookmarked events.
+func (lv *LogView) PrevBookmark() bool {
+	defer lv.fireOnCurrentChange(lv.current)
+	lv.Lock()
+	defer lv.Unlock()
+
+	return lv.gotoBookmark(false)
+}
+
+// SetMark sets the named mark on the current event, like vim `m` command. Setting a mark that already exists moves
+// it to the current event. Returns false if there is no current event
+func (lv *LogView) SetMark(name rune) bool {
+	lv.Lock()
+	defer lv.Unlock()
+
+	return lv.setMark(name)
+}
+
+// JumpToMark selects the event with the named mark and scrolls it into view, like vim `'` command.
+// Returns false if there is no such mark or the event is hidden by the filter
+func (lv *LogView) JumpToMark(name rune) bool {
+	defer lv.fireOnCurrentChange(lv.current)
+	lv.Lock()
+	defer lv.Unlock()
+
+	return lv.jumpToMark(name)
+}
+
+// GetBookmarks returns the bookmarked events in the order they were appended, including the events that were paged
+// out to the spill file (see SetSpillFile)
+func (lv *LogView) GetBookmarks() []*LogEvent {
+	lv.Lock()
+	defer lv.Unlock()
+
+	events := make([]*LogEvent, 0, len(lv.bookmarks))
+	for _, seq := range lv.bookmarks {
+		if event := lv.historyEvent(seq); event != nil {
+			events = append(events, event.AsLogEvent())
+		}
+	}
+	return events
+}
+
+// SetBookmarkColor sets the color of the bookmark and mark markers in the gutter. Default is aqua
+func (lv *LogView) SetBookmarkColor(color tcell.Color) {
+	lv.Lock()
+	defer lv.Unlock()
+
+	lv.bookmarkColor = color
+}
+
+// *******************************
+// internal implementation details
+
+func (lv *LogView) toggleBookmark() bool {
+	if lv.current == nil {
+		return false
+	}
+	seq := lv.current.seq
+	i := sort.Search(len(lv.bookmarks), func(i int) bool { return lv.bookmarks[i] >= seq })
+	bookmarked := i == len(lv.bookmarks) || lv.bookmarks[i] != seq
+	if bookmarked {
+		lv.bookmarks = append(lv.bookmarks, 0)
+		copy(lv.bookmarks[i+1:], lv.bookmarks[i:])
+		lv.bookmarks[i] = seq
+	} else {
+		lv.bookmarks = append(lv.bookmarks[:i], lv.bookmarks[i+1:]...)
+	}
+	setBookmarked(findFirstWrappedLine(lv.current), bookmarked)
+	return bookmarked
+}
+
+// isBookmarked returns true if the event with a given sequence number is bookmarked
+func (lv *LogView) isBookmarked(seq uint64) bool {
+	i := sort.Search(len(lv.bookmarks), func(i int) bool { return lv.bookmarks[i] >= seq })
+	return i < len(lv.bookmarks) && lv.bookmarks[i] == seq
+}
+
+// setBookmarked updates the bookmark flag of all the lines of the event
+func setBookmarked(event *logEventLine, bookmarked bool) {
+	for line := event; line != nil; line = line.next {
+		line.bookmarked = bookmarked
+		if line.next == nil || line.next.order <= 1 {
+			break
+		}
+	}
+}
+
+// gotoBookmark selects the next or previous visible bookmarked event, wrapping around if needed
+func (lv *LogView) gotoBookmark(forward bool) bool {
+	count := len(lv.bookmarks)
+	if count == 0 {
+		return false
+	}
+	var i int
+	if lv.current == nil {
+		if !forward {
+			i = count - 1
+		}
+	} else if forward {
+		i = sort.Search(count, func(i int) bool { return lv.bookmarks[i] > lv.current.seq })
+	} else {
+		i = sort.Search(count, func(i int) bool { return lv.bookmarks[i] >= lv.current.seq }) - 1
+	}
+	for n := 0; n < count; n++ {
+		seq := lv.bookmarks[(i+count)%count]
+		if forward {
+			i++
+		} else {
+			i--
+		}
+		if !lv.isVisibleSeq(seq) {
+			continue
+		}
+		if event := lv.showEvent(seq); event != nil {
+			lv.following = false
+			lv.moveTo(event)
+			return true
+		}
+	}
+	return false
+}
+
+func (lv *LogView) setMark(name rune) bool {
+	if lv.current == nil {
+		return false
+	}
+	if lv.marks == nil {
+		lv.marks = make(map[rune]uint64)
+	}
+	lv.marks[name] = lv.current.seq
+	return true
+}
+
+func (lv *LogView) jumpToMark(name rune) bool {
+	seq, ok := lv.marks[name]
+	if !ok || !lv.isVisibleSeq(seq) {
+		return false
+	}
+	event := lv.showEvent(seq)
+	if event == nil {
+		return false
+	}
+	lv.following = false
+	lv.moveTo(event)
+	return true
+}
+
+// markOf returns the name of the mark set on the event with a given sequence number
+func (lv *LogView) markOf(seq uint64) (rune, bool) {
+	for name, markSeq := range lv.marks {
+		if markSeq == seq {
+			return name, true
+		}
+	}
+	return 0, false
+}
+
+// forgetEvicted removes bookmarks and marks of the event that is evicted from the log view. Selection is shrunk to
+// the remaining events or cleared if all the selected events are evicted
+func (lv *LogView) forgetEvicted(event *logEventLine) {
+	s := &lv.selection
+	if s.active && s.anchor <= event.seq && s.head <= event.seq {
+		s.active = false
+	}
+	if s.anchor <= event.seq {
+		s.anchor = event.seq + 1
+	}
+	if s.head <= event.seq {
+		s.head = event.seq + 1
+	}
+
+	for len(lv.bookmarks) > 0 && lv.bookmarks[0] <= event.seq {
+		lv.bookmarks = lv.bookmarks[1:]
+	}
+	for name, seq := range lv.marks {
+		if seq == event.seq {
+			delete(lv.marks, name)
+		}
+	}
+}
+
+// historyEvent returns the event with a given sequence number from memory or from the spill file
+func (lv *LogView) historyEvent(seq uint64) *logEventLine {
+	if event := lv.index.bySeq(seq); event != nil {
+		return event
+	}
+	if lv.spill == nil || lv.spill.err != nil {
+		return nil
+	}
+	return lv.readSpilled(seq)
+}
+
+// isVisibleSeq returns true if the event with a given sequence number exists and is not hidden by the filter
+func (lv *LogView) isVisibleSeq(seq uint64) bool {
+	return lv.visibleEvent(seq) != nil
+}
+
+// visibleEvent returns the event with a given sequence number from memory or from the spill file, or nil if there is
+// no such event or it is hidden by the filter
+func (lv *LogView) visibleEvent(seq uint64) *logEventLine {
+	if event := lv.index.bySeq(seq); event != nil {
+		if event.filteredOut {
+			return nil
+		}
+		return event
+	}
+	if event := lv.historyEvent(seq); event != nil && lv.matchesFilter(event) {
+		return event
+	}
+	return nil
+}
+
+// gutterWidth returns the width of the bookmark gutter, it is only displayed if there are bookmarks or marks
+func (lv *LogView) gutterWidth() int {
+	if len(lv.bookmarks) > 0 || len(lv.marks) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// drawGutter draws the bookmark or mark of the event and returns the position where the header starts
+func (lv *LogView) drawGutter(screen tcell.Screen, x int, y int, event *logEventLine) int {
+	if lv.gutterWidth() == 0 {
+		return x
+	}
+	style := lv.defaultStyle.Foreground(lv.bookmarkColor)
+	if bg, highlighted := lv.lineBackground(event); highlighted {
+		style = style.Background(bg)
+	}
+	marker := ' '
+	if event.order <= 1 {
+		if name, ok := lv.markOf(event.seq); ok {
+			marker = name
+		} else if event.bookmarked {
+			marker = bookmarkMarker
+		}
+	}
+	screen.SetCell(x, y, style, marker)
+	return x + 1
+}
+
+// isMarkPending returns true if the mark command waits for the name of the mark
+func (lv *LogView) isMarkPending() bool {
+	lv.RLock()
+	defer lv.RUnlock()
+
+	return lv.markAction != markNone
+}
+
+// handleMarkKey completes the mark command with the name of the mark. Returns false if no mark command is waiting
+func (lv *LogView) handleMarkKey(event *tcell.EventKey) bool {
+	action := lv.markAction
+	if action == markNone {
+		return false
+	}
+	lv.markAction = markNone
+	if event.Key() != tcell.KeyRune {
+		return true // any other key cancels the command
+	}
+	if action == markSet {
+		lv.setMark(event.Rune())
+	} else {
+		lv.jumpToMark(event.Rune())
+	}
+	return true
+}
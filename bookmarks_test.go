@@ -0,0 +1,162 @@
+package logview
+
+import (
+	"fmt"
+	"github.com/gdamore/tcell/v2"
+	gui "github.com/rivo/tview"
+	"strings"
+	"testing"
+)
+
+func pressRune(lv *LogView, r rune) {
+	lv.InputHandler()(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone), func(p gui.Primitive) {})
+}
+
+func TestLogView_Bookmarks(t *testing.T) {
+	lv := NewLogView()
+	lv.pageHeight = 5
+	for i := 0; i < 20; i++ {
+		lv.AppendEvent(NewLogEvent(fmt.Sprint(i), fmt.Sprintf("event %d", i)))
+	}
+	for _, id := range []string{"3", "12", "7"} {
+		lv.ScrollToEventID(id)
+		if !lv.ToggleBookmark() {
+			t.Fatalf("Event %s must be bookmarked", id)
+		}
+	}
+
+	var ids []string
+	for _, event := range lv.GetBookmarks() {
+		ids = append(ids, event.EventID)
+	}
+	if strings.Join(ids, ",") != "3,7,12" {
+		t.Errorf("Invalid bookmarks %v", ids)
+	}
+
+	lv.ScrollToEventID("8")
+	for _, expected := range []string{"12", "3", "7"} {
+		if !lv.NextBookmark() || lv.current.EventID != expected {
+			t.Errorf("Expected bookmark %s, current %s", expected, lv.current.EventID)
+		}
+	}
+	if !lv.PrevBookmark() || !lv.PrevBookmark() || lv.current.EventID != "12" {
+		t.Errorf("Expected bookmark 12, current %s", lv.current.EventID)
+	}
+
+	lv.SetFilterRegex("event [0-9]$")
+	if !lv.NextBookmark() || lv.current.EventID != "3" {
+		t.Errorf("Hidden bookmarks must be skipped, current %s", lv.current.EventID)
+	}
+	lv.ClearFilter()
+
+	lv.ScrollToEventID("7")
+	if lv.ToggleBookmark() || len(lv.GetBookmarks()) != 2 {
+		t.Errorf("Bookmark must be removed")
+	}
+	lv.ClearBookmarks()
+	if lv.NextBookmark() || len(lv.GetBookmarks()) != 0 {
+		t.Errorf("Bookmarks must be cleared")
+	}
+}
+
+func TestLogView_BookmarksRewrap(t *testing.T) {
+	screen := tcell.NewSimulationScreen("UTF-8")
+	screen.Init()
+	screen.SetSize(40, 5)
+	lv := NewLogView()
+	lv.SetRect(0, 0, 40, 5)
+	lv.SetHighlightCurrentEvent(false)
+	lv.Draw(screen)
+	lv.AppendEvent(NewLogEvent("1", strings.Repeat("0123456789", 6)))
+	lv.AppendEvent(NewLogEvent("2", "short"))
+	lv.ScrollToEventID("1")
+	lv.ToggleBookmark()
+
+	lv.RefreshHighlights()
+	screen.SetSize(30, 5)
+	lv.SetRect(0, 0, 30, 5)
+	lv.Draw(screen)
+	screen.Show()
+
+	// gutter takes one column, so the event is wrapped at 29 characters
+	for i, line := range []string{"●01234567890123456789012345678", " 90123456789012345678901234567", " 89", " short"} {
+		if screenLine(screen, i) != line {
+			t.Errorf("Invalid line %d: '%s', expected '%s'", i, screenLine(screen, i), line)
+		}
+	}
+	for e := lv.firstEvent; e.EventID == "1"; e = e.next {
+		if !e.bookmarked {
+			t.Errorf("Wrapped line %d must be bookmarked", e.order)
+		}
+	}
+}
+
+func TestLogView_BookmarksEviction(t *testing.T) {
+	lv := NewLogView()
+	for i := 0; i < 5; i++ {
+		lv.AppendEvent(NewLogEvent(fmt.Sprint(i), fmt.Sprintf("event %d", i)))
+	}
+	lv.ScrollToEventID("1")
+	lv.ToggleBookmark()
+	lv.SetMark('a')
+	lv.ScrollToEventID("3")
+	lv.ToggleBookmark()
+	lv.SetMark('b')
+
+	lv.SetEventLimit(3)
+	if len(lv.GetBookmarks()) != 1 || lv.GetBookmarks()[0].EventID != "3" {
+		t.Errorf("Bookmark of the evicted event must be removed")
+	}
+	if lv.JumpToMark('a') || !lv.JumpToMark('b') || lv.current.EventID != "3" {
+		t.Errorf("Mark of the evicted event must be removed")
+	}
+}
+
+func TestLogView_MarkKeys(t *testing.T) {
+	lv := NewLogView()
+	lv.pageHeight = 5
+	for i := 0; i < 20; i++ {
+		lv.AppendEvent(NewLogEvent(fmt.Sprint(i), fmt.Sprintf("event %d", i)))
+	}
+	lv.ScrollToEventID("5")
+	pressRune(lv, 'b')
+	pressRune(lv, 'm')
+	pressRune(lv, 'n')
+	lv.ScrollToEventID("15")
+	pressRune(lv, 'b')
+
+	pressRune(lv, '\'')
+	pressRune(lv, 'n')
+	if lv.current.EventID != "5" {
+		t.Errorf("Expected event with mark n, current %s", lv.current.EventID)
+	}
+	pressRune(lv, ']')
+	if lv.current.EventID != "15" {
+		t.Errorf("Expected next bookmark, current %s", lv.current.EventID)
+	}
+	pressRune(lv, '[')
+	if lv.current.EventID != "5" {
+		t.Errorf("Expected previous bookmark, current %s", lv.current.EventID)
+	}
+}
+
+func TestLogView_SpillBookmarks(t *testing.T) {
+	lv := newSpillingLogView(t, 10, 0)
+	lv.AppendEvent(NewLogEvent("0", "event 0"))
+	lv.ToggleBookmark()
+	lv.SetMark('a')
+	for i := 1; i < 100; i++ {
+		lv.AppendEvent(NewLogEvent(fmt.Sprint(i), fmt.Sprintf("event %d", i)))
+	}
+
+	if bookmarks := lv.GetBookmarks(); len(bookmarks) != 1 || bookmarks[0].EventID != "0" {
+		t.Fatalf("Bookmark of the spilled event must be kept: %v", bookmarks)
+	}
+	if !lv.NextBookmark() || lv.current.EventID != "0" || !lv.current.bookmarked {
+		t.Errorf("Spilled bookmarked event must be paged in, current %s", lv.current.EventID)
+	}
+	lv.ScrollToBottom()
+	if !lv.JumpToMark('a') || lv.current.EventID != "0" {
+		t.Errorf("Spilled marked event must be paged in, current %s", lv.current.EventID)
+	}
+}
@@ -0,0 +1,396 @@
+package logview
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"strings"
+)
+
+// Identifiers of the built-in header columns. Field columns are identified by the field name
+const (
+	ColumnSource    = ":source"
+	ColumnTimestamp = ":timestamp"
+)
+
+// ColumnWidthMode defines how the width of the header column is determined
+type ColumnWidthMode int
+
+const (
+	// ColumnWidthFixed column always takes the configured width, longer values are cut
+	ColumnWidthFixed ColumnWidthMode = iota
+	// ColumnWidthClip column always takes the configured width, longer values are cut and end with an ellipsis
+	ColumnWidthClip
+	// ColumnWidthAuto column is as wide as the widest value seen so far. If the configured width is not zero, column
+	// never grows wider than that
+	ColumnWidthAuto
+)
+
+// ColumnAlignment defines alignment of the values in the header column
+type ColumnAlignment int
+
+const (
+	ColumnAlignLeft ColumnAlignment = iota
+	ColumnAlignRight
+	ColumnAlignCenter
+)
+
+type columnKind int
+
+const (
+	columnSource columnKind = iota
+	columnTimestamp
+	columnField
+)
+
+// column describes a single column of the event header
+type column struct {
+	id        string
+	kind      columnKind
+	width     int
+	widthMode ColumnWidthMode
+	autoWidth int
+	align     ColumnAlignment
+	style     tcell.Style
+	visible   bool
+	priority  int
+}
+
+func (c *column) contentWidth() int {
+	if c.widthMode == ColumnWidthAuto && (c.width == 0 || c.autoWidth < c.width) {
+		return c.autoWidth
+	}
+	return c.width
+}
+
+// headerWidth returns the width of the column including the separator
+func (c *column) headerWidth() int {
+	return c.contentWidth() + 3
+}
+
+func defaultColumns(defaultStyle tcell.Style, timestampFormat string) []*column {
+	return []*column{
+		{
+			id:        ColumnSource,
+			kind:      columnSource,
+			width:     6,
+			widthMode: ColumnWidthFixed,
+			align:     ColumnAlignRight,
+			style:     defaultStyle.Foreground(tcell.ColorDarkGoldenrod),
+		},
+		{
+			id:        ColumnTimestamp,
+			kind:      columnTimestamp,
+			width:     len(timestampFormat),
+			widthMode: ColumnWidthFixed,
+			align:     ColumnAlignLeft,
+			style:     defaultStyle.Foreground(tcell.ColorDarkOrange),
+		},
+	}
+}
+
+// AddFieldColumn adds a header column displaying the value of event field with a given name. New columns are
+// added after all the existing columns. Value is clipped to clipLength characters.
+//
+// If the column for the field already exists, its clip length and style are updated and it is made visible.
+func (lv *LogView) AddFieldColumn(name string, clipLength int, style tcell.Style) {
+	lv.Lock()
+	defer lv.Unlock()
+
+	if c := lv.findColumn(name); c != nil {
+		c.width = clipLength
+		c.style = style
+		c.visible = true
+	} else {
+		lv.columns = append(lv.columns, &column{
+			id:        name,
+			kind:      columnField,
+			width:     clipLength,
+			widthMode: ColumnWidthFixed,
+			align:     ColumnAlignRight,
+			style:     style,
+			visible:   true,
+		})
+	}
+}
+
+// RemoveFieldColumn removes the header column displaying the event field with a given name
+func (lv *LogView) RemoveFieldColumn(name string) {
+	lv.Lock()
+	defer lv.Unlock()
+
+	for i, c := range lv.columns {
+		if c.kind == columnField && c.id == name {
+			lv.columns = append(lv.columns[:i], lv.columns[i+1:]...)
+			return
+		}
+	}
+}
+
+// GetFieldColumns returns the names of event fields displayed as header columns
+func (lv *LogView) GetFieldColumns() []string {
+	lv.RLock()
+	defer lv.RUnlock()
+
+	var names []string
+	for _, c := range lv.columns {
+		if c.kind == columnField {
+			names = append(names, c.id)
+		}
+	}
+	return names
+}
+
+// SetFieldClipLength sets the maximum length of the event field value displayed in the header column
+func (lv *LogView) SetFieldClipLength(name string, length int) {
+	lv.SetColumnWidth(name, ColumnWidthFixed, length)
+}
+
+// SetFieldStyle sets the style for displaying the event field value in the header column
+func (lv *LogView) SetFieldStyle(name string, style tcell.Style) {
+	lv.SetColumnStyle(name, style)
+}
+
+// SetColumnOrder changes the order of header columns. Listed columns are displayed first in the given order,
+// followed by the rest of the columns in their current order. Unknown column ids are ignored
+func (lv *LogView) SetColumnOrder(ids ...string) {
+	lv.Lock()
+	defer lv.Unlock()
+
+	ordered := make([]*column, 0, len(lv.columns))
+	used := make(map[*column]bool)
+	for _, id := range ids {
+		if c := lv.findColumn(id); c != nil && !used[c] {
+			ordered = append(ordered, c)
+			used[c] = true
+		}
+	}
+	for _, c := range lv.columns {
+		if !used[c] {
+			ordered = append(ordered, c)
+		}
+	}
+	lv.columns = ordered
+}
+
+// GetColumnOrder returns ids of all the header columns, including hidden ones, in the display order
+func (lv *LogView) GetColumnOrder() []string {
+	lv.RLock()
+	defer lv.RUnlock()
+
+	ids := make([]string, len(lv.columns))
+	for i, c := range lv.columns {
+		ids[i] = c.id
+	}
+	return ids
+}
+
+// SetColumnWidth sets the width of the header column and how the width is applied.
+// For ColumnWidthAuto width is the maximum column width, zero means unlimited
+func (lv *LogView) SetColumnWidth(id string, mode ColumnWidthMode, width int) {
+	lv.Lock()
+	defer lv.Unlock()
+
+	if c := lv.findColumn(id); c != nil {
+		c.widthMode = mode
+		c.width = width
+		if mode == ColumnWidthAuto {
+			lv.measureColumn(c)
+		}
+	}
+}
+
+// SetColumnAlignment sets the alignment of values in the header column. Default alignment is right for event source
+// and fields and left for timestamp
+func (lv *LogView) SetColumnAlignment(id string, align ColumnAlignment) {
+	lv.Lock()
+	defer lv.Unlock()
+
+	if c := lv.findColumn(id); c != nil {
+		c.align = align
+	}
+}
+
+// SetColumnStyle sets the style of the values in the header column
+func (lv *LogView) SetColumnStyle(id string, style tcell.Style) {
+	lv.Lock()
+	defer lv.Unlock()
+
+	if c := lv.findColumn(id); c != nil {
+		c.style = style
+	}
+}
+
+// SetColumnVisible shows or hides the header column
+func (lv *LogView) SetColumnVisible(id string, visible bool) {
+	lv.Lock()
+	defer lv.Unlock()
+
+	if c := lv.findColumn(id); c != nil {
+		c.visible = visible
+	}
+}
+
+// IsColumnVisible returns whether the header column is enabled. Visible column still might not be displayed if there
+// is not enough room for it, see SetColumnPriority
+func (lv *LogView) IsColumnVisible(id string) bool {
+	lv.RLock()
+	defer lv.RUnlock()
+
+	c := lv.findColumn(id)
+	return c != nil && c.visible
+}
+
+// SetColumnPriority sets the priority of the header column. Header can take at most half of the log view width,
+// when it is wider, columns with the lowest priority are not displayed. Columns with the same priority are dropped
+// starting from the last one. Default priority is 0
+func (lv *LogView) SetColumnPriority(id string, priority int) {
+	lv.Lock()
+	defer lv.Unlock()
+
+	if c := lv.findColumn(id); c != nil {
+		c.priority = priority
+	}
+}
+
+// SetColumnSeparator sets the character displayed between header columns and the event message. Default is '|'
+func (lv *LogView) SetColumnSeparator(separator rune) {
+	lv.Lock()
+	defer lv.Unlock()
+
+	lv.columnSeparator = separator
+}
+
+// GetDisplayedColumns returns ids of the header columns that were displayed during the last draw
+func (lv *LogView) GetDisplayedColumns() []string {
+	lv.RLock()
+	defer lv.RUnlock()
+
+	ids := make([]string, len(lv.shownColumns))
+	for i, c := range lv.shownColumns {
+		ids[i] = c.id
+	}
+	return ids
+}
+
+// *******************************
+// internal implementation details
+
+func (lv *LogView) findColumn(id string) *column {
+	for _, c := range lv.columns {
+		if c.id == id {
+			return c
+		}
+	}
+	return nil
+}
+
+// layoutColumns selects the columns to display, dropping the lowest priority columns until the header takes
+// less than half of the page
+func (lv *LogView) layoutColumns() {
+	shown := lv.shownColumns[:0]
+	width := 0
+	for _, c := range lv.columns {
+		if c.visible {
+			shown = append(shown, c)
+			width += c.headerWidth()
+		}
+	}
+	for len(shown) > 0 && width >= lv.fullPageWidth/2 {
+		drop := len(shown) - 1
+		for i := drop - 1; i >= 0; i-- {
+			if shown[i].priority < shown[drop].priority {
+				drop = i
+			}
+		}
+		width -= shown[drop].headerWidth()
+		shown = append(shown[:drop], shown[drop+1:]...)
+	}
+	lv.shownColumns = shown
+}
+
+// headerWidth returns the width of the header of the log line
+func (lv *LogView) headerWidth() int {
+	w := lv.gutterWidth()
+	for _, c := range lv.shownColumns {
+		w += c.headerWidth()
+	}
+	return w
+}
+
+func (lv *LogView) columnValue(c *column, event *logEventLine) string {
+	switch c.kind {
+	case columnSource:
+		return event.Source
+	case columnTimestamp:
+		return event.Timestamp.Format(lv.timestampFormat)
+	default:
+		return event.Fields[c.id]
+	}
+}
+
+// measureColumns updates the width of auto-sized columns to fit the event
+func (lv *LogView) measureColumns(event *logEventLine) {
+	for _, c := range lv.columns {
+		if c.widthMode == ColumnWidthAuto {
+			if w := runesWidth([]rune(lv.columnValue(c, event))); w > c.autoWidth {
+				c.autoWidth = w
+			}
+		}
+	}
+}
+
+// measureColumn recalculates the width of auto-sized column for all the events
+func (lv *LogView) measureColumn(c *column) {
+	c.autoWidth = 0
+	for event := lv.firstEvent; event != nil; event = event.next {
+		if event.order <= 1 {
+			if w := runesWidth([]rune(lv.columnValue(c, event))); w > c.autoWidth {
+				c.autoWidth = w
+			}
+		}
+	}
+}
+
+// fitColumnValue clips or pads the value to the column width according to the column alignment
+func fitColumnValue(c *column, value string) []rune {
+	width := c.contentWidth()
+	var tail rune
+	if c.widthMode == ColumnWidthClip {
+		tail = '…'
+	}
+	runes, w := fitRunes([]rune(value), width, tail)
+	padding := width - w
+	var left int
+	switch c.align {
+	case ColumnAlignRight:
+		left = padding
+	case ColumnAlignCenter:
+		left = padding / 2
+	}
+	return []rune(strings.Repeat(" ", left) + string(runes) + strings.Repeat(" ", padding-left))
+}
+
+// drawHeader draws the header columns of the event and returns the position where event message starts
+func (lv *LogView) drawHeader(screen tcell.Screen, x int, y int, event *logEventLine) int {
+	bg, highlighted := lv.lineBackground(event)
+	separatorStyle := lv.defaultStyle
+	if highlighted {
+		separatorStyle = lv.defaultStyle.Background(bg)
+	}
+	for _, c := range lv.shownColumns {
+		if event.order > 1 { // continuation of the wrapped event
+			x += c.headerWidth()
+			continue
+		}
+		style := c.style
+		if highlighted {
+			style = separatorStyle
+		}
+		printString(screen, x, y, string(fitColumnValue(c, lv.columnValue(c, event))), style)
+		x += c.contentWidth()
+		screen.SetCell(x, y, separatorStyle, ' ')
+		screen.SetCell(x+1, y, separatorStyle, lv.columnSeparator)
+		screen.SetCell(x+2, y, separatorStyle, ' ')
+		x += 3
+	}
+	return x
+}
@@ -0,0 +1,117 @@
+package logview
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"testing"
+	"time"
+)
+
+func TestLogView_FieldColumns(t *testing.T) {
+	screen := tcell.NewSimulationScreen("UTF-8")
+	screen.Init()
+	screen.SetSize(40, 5)
+	lv := NewLogView()
+	lv.SetRect(0, 0, 40, 5)
+	lv.AppendEvent(&LogEvent{
+		EventID:   "1",
+		Timestamp: time.Now(),
+		Message:   "request served",
+		Fields:    map[string]string{"user": "bob", "request_id": "abcdefgh"},
+	})
+	lv.AddFieldColumn("user", 5, tcell.StyleDefault)
+	lv.AddFieldColumn("request_id", 4, tcell.StyleDefault)
+	lv.Draw(screen)
+	screen.Show()
+
+	if line := screenLine(screen, 0); line != "  bob | abcd | request served" {
+		t.Errorf("Invalid field columns drawn: '%s'", line)
+	}
+	if event := lv.GetCurrentEvent(); event.Fields["user"] != "bob" {
+		t.Errorf("Fields must be available in the current event: %v", event.Fields)
+	}
+
+	lv.RemoveFieldColumn("user")
+	lv.SetFieldClipLength("request_id", 8)
+	if columns := lv.GetFieldColumns(); len(columns) != 1 || columns[0] != "request_id" {
+		t.Errorf("Invalid field columns: %v", columns)
+	}
+	lv.Draw(screen)
+	screen.Show()
+	if line := screenLine(screen, 0); line != "abcdefgh | request served" {
+		t.Errorf("Invalid field columns drawn: '%s'", line)
+	}
+}
+
+func TestLogView_ColumnLayout(t *testing.T) {
+	screen := tcell.NewSimulationScreen("UTF-8")
+	screen.Init()
+	screen.SetSize(60, 5)
+	lv := NewLogView()
+	lv.SetRect(0, 0, 60, 5)
+	lv.AppendEvent(&LogEvent{
+		EventID:   "1",
+		Source:    "database",
+		Timestamp: time.Date(2021, 3, 6, 21, 16, 34, 0, time.UTC),
+		Message:   "query",
+		Fields:    map[string]string{"user": "bob"},
+	})
+	lv.SetShowSource(true)
+	lv.SetShowTimestamp(true)
+	lv.SetTimestampFormat("15:04")
+	lv.AddFieldColumn("user", 5, tcell.StyleDefault)
+	lv.SetColumnOrder("user", ColumnTimestamp)
+	lv.SetColumnWidth(ColumnSource, ColumnWidthClip, 5)
+	lv.SetColumnAlignment("user", ColumnAlignLeft)
+	lv.SetColumnSeparator('│')
+	lv.Draw(screen)
+	screen.Show()
+
+	if order := lv.GetColumnOrder(); len(order) != 3 || order[0] != "user" || order[1] != ColumnTimestamp || order[2] != ColumnSource {
+		t.Errorf("Invalid column order: %v", order)
+	}
+	if line := screenLine(screen, 0); line != "bob   │ 21:16 │ data… │ query" {
+		t.Errorf("Invalid columns drawn: '%s'", line)
+	}
+
+	lv.SetColumnWidth(ColumnSource, ColumnWidthAuto, 0)
+	lv.SetColumnAlignment(ColumnSource, ColumnAlignCenter)
+	lv.AppendEvent(&LogEvent{EventID: "2", Source: "web", Timestamp: time.Date(2021, 3, 6, 21, 17, 0, 0, time.UTC), Message: "get"})
+	lv.Draw(screen)
+	screen.Show()
+	if line := screenLine(screen, 1); line != "      │ 21:17 │   web    │ get" {
+		t.Errorf("Auto-sized column must fit the widest value: '%s'", line)
+	}
+}
+
+func TestLogView_ColumnPriority(t *testing.T) {
+	screen := tcell.NewSimulationScreen("UTF-8")
+	screen.Init()
+	screen.SetSize(40, 5)
+	lv := NewLogView()
+	lv.SetRect(0, 0, 40, 5)
+	lv.AppendEvent(&LogEvent{EventID: "1", Source: "main", Timestamp: time.Now(), Message: "started"})
+	lv.SetShowSource(true)
+	lv.SetShowTimestamp(true)
+	lv.SetColumnPriority(ColumnSource, 1)
+	lv.Draw(screen)
+
+	// source (9) + timestamp (15) is wider than half of the page, lower priority timestamp is dropped
+	if columns := lv.GetDisplayedColumns(); len(columns) != 1 || columns[0] != ColumnSource {
+		t.Errorf("Timestamp column should be dropped, displayed: %v", columns)
+	}
+	if lv.pageWidth != 31 {
+		t.Errorf("Message width should account for displayed columns only, width=%d", lv.pageWidth)
+	}
+
+	lv.SetColumnPriority(ColumnSource, -1)
+	lv.Draw(screen)
+	if columns := lv.GetDisplayedColumns(); len(columns) != 1 || columns[0] != ColumnTimestamp {
+		t.Errorf("Lowest priority source column should be dropped, displayed: %v", columns)
+	}
+
+	lv.SetRect(0, 0, 80, 5)
+	lv.Draw(screen)
+	if columns := lv.GetDisplayedColumns(); len(columns) != 2 {
+		t.Errorf("All columns should be displayed on a wide page, displayed: %v", columns)
+	}
+}
@@ -3,13 +3,19 @@ package logview
 import (
 	"fmt"
 	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-runewidth"
+	"math"
+	"strconv"
 	"strings"
 	"time"
+	"unicode"
 )
 
 // LogLevel represents the log level
-// LogView recognizes three log levels: Info, Warning and Error
-// Warning and Error events can be highlighted
+// LogView recognizes eight log levels from Trace to Fatal. Events of each level can be highlighted with its own colors.
+//
+// Numeric values of log levels do not follow their severity, LogLevelInfo is zero value for compatibility.
+// Use Severity to compare log levels.
 type LogLevel uint
 
 const (
@@ -21,8 +27,85 @@ const (
 	LogLevelError
 	// LogLevelAll is used for building histograms only as a placeholder for all log levels
 	LogLevelAll
+	// LogLevelTrace is the level for the most detailed tracing events
+	LogLevelTrace
+	// LogLevelDebug is the level for debugging events
+	LogLevelDebug
+	// LogLevelNotice is the level for normal, but significant events
+	LogLevelNotice
+	// LogLevelCritical is the level for critical conditions
+	LogLevelCritical
+	// LogLevelFatal is the level for events after which the application cannot continue
+	LogLevelFatal
 )
 
+// LogLevels contains all log levels in order of increasing severity
+var LogLevels = []LogLevel{
+	LogLevelTrace,
+	LogLevelDebug,
+	LogLevelInfo,
+	LogLevelNotice,
+	LogLevelWarning,
+	LogLevelError,
+	LogLevelCritical,
+	LogLevelFatal,
+}
+
+var logLevelNames = map[LogLevel]string{
+	LogLevelTrace:    "TRACE",
+	LogLevelDebug:    "DEBUG",
+	LogLevelInfo:     "INFO",
+	LogLevelNotice:   "NOTICE",
+	LogLevelWarning:  "WARN",
+	LogLevelError:    "ERROR",
+	LogLevelCritical: "CRIT",
+	LogLevelFatal:    "FATAL",
+	LogLevelAll:      "ALL",
+}
+
+var logLevelSpellings = map[string]LogLevel{
+	"t": LogLevelTrace, "trc": LogLevelTrace, "trace": LogLevelTrace, "finest": LogLevelTrace, "finer": LogLevelTrace,
+	"verbose": LogLevelTrace, "v": LogLevelTrace,
+	"d": LogLevelDebug, "dbg": LogLevelDebug, "debug": LogLevelDebug, "fine": LogLevelDebug,
+	"i": LogLevelInfo, "inf": LogLevelInfo, "info": LogLevelInfo, "information": LogLevelInfo, "informational": LogLevelInfo,
+	"n": LogLevelNotice, "note": LogLevelNotice, "notice": LogLevelNotice,
+	"w": LogLevelWarning, "wrn": LogLevelWarning, "warn": LogLevelWarning, "warning": LogLevelWarning,
+	"e": LogLevelError, "err": LogLevelError, "error": LogLevelError, "severe": LogLevelError,
+	"c": LogLevelCritical, "crit": LogLevelCritical, "critical": LogLevelCritical,
+	"a": LogLevelCritical, "alert": LogLevelCritical,
+	"f": LogLevelFatal, "ftl": LogLevelFatal, "fatal": LogLevelFatal, "emerg": LogLevelFatal,
+	"emergency": LogLevelFatal, "panic": LogLevelFatal,
+}
+
+// ParseLogLevel converts a textual representation of log level into LogLevel.
+//
+// Parsing is case-insensitive and understands common spellings and abbreviations, like "WARN", "warning", "W", "E",
+// "err", "crit", "emerg", etc. For unknown values LogLevelInfo is returned along with an error.
+func ParseLogLevel(level string) (LogLevel, error) {
+	if l, ok := logLevelSpellings[strings.ToLower(strings.TrimSpace(level))]; ok {
+		return l, nil
+	}
+	return LogLevelInfo, fmt.Errorf("unknown log level %q", level)
+}
+
+// Severity returns rank of the log level, more severe levels have higher rank. LogLevelAll has the lowest rank.
+func (l LogLevel) Severity() int {
+	for i, level := range LogLevels {
+		if level == l {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// String returns the name of the log level
+func (l LogLevel) String() string {
+	if name, ok := logLevelNames[l]; ok {
+		return name
+	}
+	return "LogLevel(" + strconv.Itoa(int(l)) + ")"
+}
+
 // LogEvent that can be added to LogView.
 // Contains following fields:
 //
@@ -35,31 +118,146 @@ const (
 // - Level - the severity level of an event. Can be used to highlight errors and warnings
 //
 // - Message - the event contents
+//
+// - Fields - optional key/value pairs, i.e. request id, user, duration. Fields can be displayed as header columns
 type LogEvent struct {
 	EventID   string
 	Source    string
 	Timestamp time.Time
 	Level     LogLevel
 	Message   string
+	Fields    map[string]string
 }
 
 func NewLogEvent(eventID string, message string) *LogEvent {
-	// expand tabs to 4 spaces (not exactly how it should be done, but will work for now)
-	msg := strings.Replace(message, "\t", "    ", -1)
-
 	return &LogEvent{
 		EventID: eventID,
 		Level:   LogLevelInfo,
-		Message: msg,
+		Message: message,
+	}
+}
+
+// expandTabs replaces tab characters with spaces up to the next tab stop. Tab stops are every tabWidth cells
+// from the beginning of each line. If tabWidth is zero or less, tabs are not expanded
+func expandTabs(message string, tabWidth int) []rune {
+	runes := []rune(message)
+	if tabWidth <= 0 || !strings.ContainsRune(message, '\t') {
+		return runes
+	}
+	result := make([]rune, 0, len(runes)+tabWidth)
+	column := 0
+	for _, r := range runes {
+		switch r {
+		case '\t':
+			spaces := tabWidth - column%tabWidth
+			for i := 0; i < spaces; i++ {
+				result = append(result, ' ')
+			}
+			column += spaces
+		case '\n':
+			result = append(result, r)
+			column = 0
+		default:
+			result = append(result, r)
+			column += cellWidth(r)
+		}
 	}
+	return result
 }
 
 // printString is the most dump printing function. It just prints the string starting at x,y with
 // a given style. No checks whatsoever are performed
 func printString(screen tcell.Screen, x int, y int, text string, style tcell.Style) {
-	for i, c := range text {
-		screen.SetCell(x+i, y, style, c)
+	printRunes(screen, x, y, math.MaxInt32, []rune(text), func(int) tcell.Style {
+		return style
+	})
+}
+
+// printRunes prints runes starting at x,y using at most width cells. Wide characters take two cells and combining
+// characters are drawn in the same cell with the preceding character. styleAt returns the style for the rune at
+// a given index.
+//
+// Returns the number of runes printed and the number of cells used
+func printRunes(screen tcell.Screen, x int, y int, width int, runes []rune, styleAt func(i int) tcell.Style) (int, int) {
+	pos, cells := 0, 0
+	for pos < len(runes) {
+		mainc := runes[pos]
+		w := cellWidth(mainc)
+		if w == 0 { // combining character without a base character
+			w = 1
+		}
+		if cells+w > width {
+			break
+		}
+		end := pos + 1
+		for end < len(runes) && cellWidth(runes[end]) == 0 {
+			end++
+		}
+		var combc []rune
+		if end > pos+1 {
+			combc = runes[pos+1 : end]
+		}
+		if unicode.IsControl(mainc) {
+			mainc = ' '
+		}
+		screen.SetContent(x+cells, y, mainc, combc, styleAt(pos))
+		cells += w
+		pos = end
+	}
+	return pos, cells
+}
+
+// cellWidth returns the number of screen cells taken by the rune. Combining characters take zero cells, they are
+// displayed in the cell of the preceding character. Control characters are displayed as a single space
+func cellWidth(r rune) int {
+	if unicode.IsControl(r) {
+		return 1
 	}
+	return runewidth.RuneWidth(r)
+}
+
+// runesWidth returns the number of screen cells taken by the runes
+func runesWidth(runes []rune) int {
+	w := 0
+	for _, r := range runes {
+		w += cellWidth(r)
+	}
+	return w
+}
+
+// skipCells returns the position of the first rune in runes[start:end] that starts at least cells cells after start
+func skipCells(runes []rune, start int, end int, cells int) int {
+	pos, used := start, 0
+	for pos < end && used < cells {
+		used += cellWidth(runes[pos])
+		pos++
+	}
+	for pos < end && cellWidth(runes[pos]) == 0 { // don't start from a combining character
+		pos++
+	}
+	return pos
+}
+
+// fitRunes truncates runes to fit into width cells. If runes are truncated and tail is not 0 it is added
+// to the end. Returns the truncated runes and their width
+func fitRunes(runes []rune, width int, tail rune) ([]rune, int) {
+	if w := runesWidth(runes); w <= width {
+		return runes, w
+	}
+	if tail != 0 {
+		width -= cellWidth(tail)
+	}
+	pos, used := 0, 0
+	for pos < len(runes) && used+cellWidth(runes[pos]) <= width {
+		used += cellWidth(runes[pos])
+		pos++
+	}
+	result := append([]rune{}, runes[:pos]...)
+	if tail != 0 && width >= 0 {
+		result = append(result, tail)
+		used += cellWidth(tail)
+	}
+	return result, used
 }
 
 func formatValue(value int) string {
@@ -81,3 +279,50 @@ func minInt(a, b int) int {
 		return b
 	}
 }
+
+func copyFields(fields map[string]string) map[string]string {
+	if len(fields) == 0 {
+		return nil
+	}
+	result := make(map[string]string, len(fields))
+	for k, v := range fields {
+		result[k] = v
+	}
+	return result
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	} else {
+		return b
+	}
+}
+
+func containsNewLine(runes []rune) bool {
+	for _, r := range runes {
+		if r == '\n' {
+			return true
+		}
+	}
+	return false
+}
+
+// addAttributes adds attributes to the style. Underline is set with Style.Underline, as tcell draws underline
+// according to the underline style
+func addAttributes(style tcell.Style, attrs tcell.AttrMask) tcell.Style {
+	_, _, current := style.Decompose()
+	style = style.Attributes(current | attrs)
+	if attrs&tcell.AttrUnderline != 0 {
+		style = style.Underline(true)
+	}
+	return style
+}
+
+func minUint(a, b uint) uint {
+	if a < b {
+		return a
+	} else {
+		return b
+	}
+}
@@ -0,0 +1,59 @@
+package logview
+
+import "testing"
+
+func TestParseLogLevel(t *testing.T) {
+	expected := map[string]LogLevel{
+		"TRACE":    LogLevelTrace,
+		"dbg":      LogLevelDebug,
+		"Info":     LogLevelInfo,
+		"notice":   LogLevelNotice,
+		"WARN":     LogLevelWarning,
+		"warning":  LogLevelWarning,
+		"E":        LogLevelError,
+		" crit ":   LogLevelCritical,
+		"critical": LogLevelCritical,
+		"emerg":    LogLevelFatal,
+		"FATAL":    LogLevelFatal,
+	}
+	for text, level := range expected {
+		l, err := ParseLogLevel(text)
+		if err != nil || l != level {
+			t.Errorf("Expected %v for '%s', got %v, err=%v", level, text, l, err)
+		}
+	}
+
+	if l, err := ParseLogLevel("bogus"); err == nil || l != LogLevelInfo {
+		t.Errorf("Unknown level must be reported as error")
+	}
+}
+
+func TestLogLevel_Severity(t *testing.T) {
+	for i := 1; i < len(LogLevels); i++ {
+		if LogLevels[i-1].Severity() >= LogLevels[i].Severity() {
+			t.Errorf("%v must be less severe than %v", LogLevels[i-1], LogLevels[i])
+		}
+	}
+	if LogLevelAll.Severity() >= LogLevelTrace.Severity() {
+		t.Errorf("LogLevelAll must have the lowest severity")
+	}
+}
+
+func TestExpandTabs(t *testing.T) {
+	tests := []struct {
+		message  string
+		width    int
+		expected string
+	}{
+		{"a\tb", 4, "a   b"},
+		{"abcd\te", 4, "abcd    e"},
+		{"\tx\n12\ty", 4, "    x\n12  y"},
+		{"日本\tx", 4, "日本    x"},
+		{"a\tb", 0, "a\tb"},
+	}
+	for _, test := range tests {
+		if result := string(expandTabs(test.message, test.width)); result != test.expected {
+			t.Errorf("expandTabs(%q, %d) = %q, expected %q", test.message, test.width, result, test.expected)
+		}
+	}
+}
@@ -0,0 +1,409 @@
+package logview
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/gdamore/tcell/v2"
+	"html"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ExportFormat is the format of the events written by LogView.Export
+type ExportFormat int
+
+const (
+	// ExportText writes events as they are displayed, visible header columns followed by the message. Lines of the
+	// multiline messages are aligned with the first line
+	ExportText ExportFormat = iota
+	// ExportJSON writes one JSON object per line with all the fields of the event
+	ExportJSON
+	// ExportCSV writes events as comma-separated values with a header row. Every event field gets its own column
+	ExportCSV
+	// ExportHTML writes an HTML document with the events highlighted the same way they are displayed
+	ExportHTML
+)
+
+// ExportScope defines which events are written by LogView.Export
+type ExportScope int
+
+const (
+	// ExportAll exports all the events, including the ones hidden by the filter and spilled to disk
+	ExportAll ExportScope = iota
+	// ExportFiltered exports the events that are not hidden by the filter
+	ExportFiltered
+	// ExportSelected exports the selected events or the current event if nothing is selected
+	ExportSelected
+)
+
+// Export writes the events to w in a given format. Events are exported in the order they were appended, events paged
+// out to the spill file are read from the file. Text and HTML formats use the current header column layout and
+// highlighting settings.
+//
+// Events are read and written in chunks, log view is only locked while a chunk is collected, so it is safe to export
+// a large log to a slow writer. Events appended after the export started are not exported. CSV format reads the events
+// twice, the names of the event fields are collected for the header row first
+func (lv *LogView) Export(w io.Writer, format ExportFormat, scope ExportScope) error {
+	return lv.export(w, format, scope, func(*logEventLine) bool { return true })
+}
+
+// ExportTimeRange writes the events that are not hidden by the filter and have timestamp in range [from, to) to w in
+// a given format. See Export
+func (lv *LogView) ExportTimeRange(w io.Writer, format ExportFormat, from time.Time, to time.Time) error {
+	return lv.export(w, format, ExportFiltered, func(event *logEventLine) bool {
+		return !event.Timestamp.Before(from) && event.Timestamp.Before(to)
+	})
+}
+
+// *******************************
+// internal implementation details
+
+// exportedEvent is the JSON representation of the event
+type exportedEvent struct {
+	EventID   string            `json:"id"`
+	Source    string            `json:"source"`
+	Timestamp time.Time         `json:"timestamp"`
+	Level     string            `json:"level"`
+	Message   string            `json:"message"`
+	Fields    map[string]string `json:"fields,omitempty"`
+}
+
+// exportChunkSize is the number of events collected at once while exporting, the log view is locked while the chunk
+// is collected
+const exportChunkSize = 500
+
+// exporter streams the events in a range of sequence numbers in chunks, so only a chunk of events is kept in memory
+// and the log view is not locked while the events are written. Header column layout is taken when the export starts
+type exporter struct {
+	lv     *LogView
+	format ExportFormat
+	accept func(event *logEventLine) bool
+	// range of the sequence numbers of the exported events
+	from  uint64
+	to    uint64
+	empty bool
+	// events hidden by the filter are skipped
+	visibleOnly bool
+	// copies of the visible header columns, only for the text and HTML formats
+	columns      []*column
+	headerStyles []tcell.Style
+	separator    rune
+	style        tcell.Style
+}
+
+// exportedLine is the unwrapped copy of the event with the fitted values of its header columns
+type exportedLine struct {
+	event  *logEventLine
+	header []string
+}
+
+func (lv *LogView) export(w io.Writer, format ExportFormat, scope ExportScope, accept func(event *logEventLine) bool) error {
+	if format < ExportText || format > ExportHTML {
+		return fmt.Errorf("unknown export format %d", format)
+	}
+	lv.Lock()
+	exp := lv.newExporter(format, scope, accept)
+	lv.Unlock()
+
+	out := bufio.NewWriter(w)
+	var err error
+	switch format {
+	case ExportText:
+		err = exp.writeText(out)
+	case ExportJSON:
+		err = exp.writeJSON(out)
+	case ExportCSV:
+		err = exp.writeCSV(out)
+	case ExportHTML:
+		err = exp.writeHTML(out)
+	}
+	if err != nil {
+		return err
+	}
+	return out.Flush()
+}
+
+// newExporter takes the range of the events in scope and the header column layout
+func (lv *LogView) newExporter(format ExportFormat, scope ExportScope, accept func(event *logEventLine) bool) *exporter {
+	exp := &exporter{lv: lv, format: format, accept: accept, separator: lv.columnSeparator, style: lv.defaultStyle}
+	switch {
+	case scope == ExportSelected && lv.selection.active:
+		exp.from, exp.to = lv.selection.bounds()
+		exp.visibleOnly = true
+	case scope == ExportSelected && lv.current != nil:
+		exp.from, exp.to = lv.current.seq, lv.current.seq
+	case scope == ExportSelected || (lv.firstEvent == nil && !lv.hasSpilledHistory()):
+		exp.empty = true
+	default:
+		exp.from, exp.to = lv.index.firstSeq, lv.lastSeq()
+		if lv.hasSpilledHistory() {
+			exp.from = lv.spill.baseSeq
+		}
+		exp.visibleOnly = scope == ExportFiltered
+	}
+	if format != ExportText && format != ExportHTML {
+		return exp
+	}
+	for _, c := range lv.columns {
+		if c.visible {
+			columnCopy := *c
+			exp.columns = append(exp.columns, &columnCopy)
+			exp.headerStyles = append(exp.headerStyles, c.style)
+		}
+	}
+	return exp
+}
+
+// each calls fn for every exported event in order. Events are collected in chunks, the log view is only locked while
+// the chunk is collected
+func (x *exporter) each(fn func(line exportedLine) error) error {
+	if x.empty {
+		return nil
+	}
+	for seq := x.from; seq <= x.to; {
+		lines, next, err := x.collect(seq)
+		if err != nil {
+			return err
+		}
+		for _, line := range lines {
+			if err = fn(line); err != nil {
+				return err
+			}
+		}
+		seq = next
+	}
+	return nil
+}
+
+// collect returns the next chunk of the exported events starting with a given sequence number and the sequence number
+// the next chunk starts with. Events that were evicted from the log view since the export started are skipped
+func (x *exporter) collect(seq uint64) ([]exportedLine, uint64, error) {
+	lv := x.lv
+	lv.Lock()
+	defer lv.Unlock()
+
+	lines := make([]exportedLine, 0, exportChunkSize)
+	for ; seq <= x.to && len(lines) < exportChunkSize; seq++ {
+		event := lv.index.bySeq(seq)
+		hidden := event != nil && event.filteredOut
+		if event == nil {
+			if event = lv.historyEvent(seq); event == nil {
+				if lv.spill != nil && lv.spill.err != nil {
+					return nil, seq, lv.spill.err
+				}
+				continue
+			}
+			hidden = !lv.matchesFilter(event)
+		}
+		if (x.visibleOnly && hidden) || !x.accept(event) {
+			continue
+		}
+		line := exportedLine{event: lv.exportCopy(event, x.format == ExportHTML)}
+		for _, c := range x.columns {
+			line.header = append(line.header, string(fitColumnValue(c, lv.columnValue(c, event))))
+		}
+		lines = append(lines, line)
+	}
+	return lines, seq, nil
+}
+
+// exportCopy returns the unwrapped copy of the event. If colorize is true, the copy is highlighted with the current
+// settings
+func (lv *LogView) exportCopy(event *logEventLine, colorize bool) *logEventLine {
+	c := event.copy()
+	c.previous = nil
+	c.next = nil
+	c.order = 0
+	c.start = 0
+	c.end = len(c.Runes)
+	if colorize && c.colorGeneration != lv.colorGeneration {
+		lv.colorize(c)
+	}
+	return c
+}
+
+// header returns the header of the event as it is displayed, followed by the indent for the following lines
+// of multiline messages
+func (x *exporter) header(line exportedLine) (string, string) {
+	var header strings.Builder
+	for _, value := range line.header {
+		header.WriteString(value)
+		header.WriteString(" " + string(x.separator) + " ")
+	}
+	return header.String(), strings.Repeat(" ", runesWidth([]rune(header.String())))
+}
+
+func (x *exporter) writeText(w *bufio.Writer) error {
+	return x.each(func(line exportedLine) error {
+		header, indent := x.header(line)
+		if _, err := w.WriteString(header); err != nil {
+			return err
+		}
+		if _, err := w.WriteString(strings.ReplaceAll(line.event.message(), "\n", "\n"+indent)); err != nil {
+			return err
+		}
+		return w.WriteByte('\n')
+	})
+}
+
+func (x *exporter) writeJSON(w *bufio.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	return x.each(func(line exportedLine) error {
+		event := line.event
+		return encoder.Encode(exportedEvent{
+			EventID:   event.EventID,
+			Source:    event.Source,
+			Timestamp: event.Timestamp,
+			Level:     event.Level.String(),
+			Message:   event.originalMessage(),
+			Fields:    event.Fields,
+		})
+	})
+}
+
+// writeCSV reads the events twice, the first pass collects the names of the event fields for the header row
+func (x *exporter) writeCSV(w *bufio.Writer) error {
+	names := make(map[string]bool)
+	err := x.each(func(line exportedLine) error {
+		for name := range line.event.Fields {
+			names[name] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	fields := make([]string, 0, len(names))
+	for name := range names {
+		fields = append(fields, name)
+	}
+	sort.Strings(fields)
+
+	writer := csv.NewWriter(w)
+	if err = writer.Write(append([]string{"id", "source", "timestamp", "level", "message"}, fields...)); err != nil {
+		return err
+	}
+	err = x.each(func(line exportedLine) error {
+		event := line.event
+		record := []string{event.EventID, event.Source, event.Timestamp.Format(time.RFC3339Nano), event.Level.String(),
+			event.originalMessage()}
+		for _, name := range fields {
+			record = append(record, event.Fields[name])
+		}
+		return writer.Write(record)
+	})
+	if err != nil {
+		return err
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func (x *exporter) writeHTML(w *bufio.Writer) error {
+	_, err := w.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>Log events</title>\n</head>\n" +
+		"<body>\n<pre style=\"" + cssStyle(x.style) + "\">\n")
+	if err != nil {
+		return err
+	}
+	err = x.each(func(line exportedLine) error {
+		event := line.event
+		_, indent := x.header(line)
+		for j, value := range line.header {
+			if err := writeStyledText(w, value, x.headerStyles[j], ""); err != nil {
+				return err
+			}
+			if _, err := w.WriteString(html.EscapeString(" " + string(x.separator) + " ")); err != nil {
+				return err
+			}
+		}
+		if len(event.styleSpans) == 0 {
+			if err := writeStyledText(w, event.message(), x.style, indent); err != nil {
+				return err
+			}
+		}
+		for _, span := range event.styleSpans {
+			// the last span may cover the position after the end of the message
+			if end := minInt(span.end, len(event.Runes)); span.start < end {
+				if err := writeStyledText(w, string(event.Runes[span.start:end]), span.style, indent); err != nil {
+					return err
+				}
+			}
+		}
+		return w.WriteByte('\n')
+	})
+	if err != nil {
+		return err
+	}
+	_, err = w.WriteString("</pre>\n</body>\n</html>\n")
+	return err
+}
+
+// writeStyledText writes the text as an HTML span with a given style. New lines in the text are followed by the indent
+func writeStyledText(w *bufio.Writer, text string, style tcell.Style, indent string) error {
+	if text == "" {
+		return nil
+	}
+	for i, line := range strings.Split(text, "\n") {
+		if i > 0 {
+			if _, err := w.WriteString("\n" + indent); err != nil {
+				return err
+			}
+		}
+		if line == "" {
+			continue
+		}
+		var err error
+		if css := cssStyle(style); css != "" {
+			_, err = w.WriteString("<span style=\"" + css + "\">" + html.EscapeString(line) + "</span>")
+		} else {
+			_, err = w.WriteString(html.EscapeString(line))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cssStyle converts the style into CSS declarations. Default colors are not included
+func cssStyle(style tcell.Style) string {
+	fg, bg, attrs := style.Decompose()
+	if attrs&tcell.AttrReverse != 0 {
+		fg, bg = bg, fg
+	}
+	var css []string
+	if fg != tcell.ColorDefault {
+		css = append(css, fmt.Sprintf("color:#%06x", fg.Hex()))
+	}
+	if bg != tcell.ColorDefault {
+		css = append(css, fmt.Sprintf("background-color:#%06x", bg.Hex()))
+	}
+	if attrs&tcell.AttrBold != 0 {
+		css = append(css, "font-weight:bold")
+	}
+	if attrs&tcell.AttrItalic != 0 {
+		css = append(css, "font-style:italic")
+	}
+	if attrs&tcell.AttrDim != 0 {
+		css = append(css, "opacity:0.6")
+	}
+	var decorations []string
+	if attrs&tcell.AttrUnderline != 0 {
+		decorations = append(decorations, "underline")
+	}
+	if attrs&tcell.AttrStrikeThrough != 0 {
+		decorations = append(decorations, "line-through")
+	}
+	if attrs&tcell.AttrBlink != 0 {
+		decorations = append(decorations, "blink")
+	}
+	if len(decorations) > 0 {
+		css = append(css, "text-decoration:"+strings.Join(decorations, " "))
+	}
+	return strings.Join(css, ";")
+}
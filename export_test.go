@@ -0,0 +1,234 @@
+package logview
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gdamore/tcell/v2"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newExportLogView() *LogView {
+	lv := NewLogView()
+	lv.SetShowSource(true)
+	lv.SetSourceClipLength(4)
+	lv.SetShowTimestamp(true)
+	lv.SetTimestampFormat("15:04:05")
+	start := time.Date(2022, 1, 1, 10, 0, 0, 0, time.UTC)
+	for i, level := range []LogLevel{LogLevelInfo, LogLevelError, LogLevelInfo, LogLevelWarning} {
+		lv.AppendEvent(&LogEvent{
+			EventID:   fmt.Sprint(i),
+			Source:    "app",
+			Timestamp: start.Add(time.Duration(i) * time.Minute),
+			Level:     level,
+			Message:   fmt.Sprintf("event <%d>", i),
+			Fields:    map[string]string{"user": fmt.Sprintf("user%d", i)},
+		})
+	}
+	return lv
+}
+
+func exportString(t *testing.T, lv *LogView, format ExportFormat, scope ExportScope) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := lv.Export(&buf, format, scope); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	return buf.String()
+}
+
+func TestLogView_ExportText(t *testing.T) {
+	lv := newExportLogView()
+	lv.AppendEvent(&LogEvent{EventID: "4", Source: "db", Timestamp: time.Date(2022, 1, 1, 11, 0, 0, 0, time.UTC),
+		Message: "multiline\nmessage"})
+
+	expected := " app | 10:00:00 | event <0>\n" +
+		" app | 10:01:00 | event <1>\n" +
+		" app | 10:02:00 | event <2>\n" +
+		" app | 10:03:00 | event <3>\n" +
+		"  db | 11:00:00 | multiline\n" +
+		"                  message\n"
+	if text := exportString(t, lv, ExportText, ExportAll); text != expected {
+		t.Errorf("Invalid text export:\n%s", text)
+	}
+
+	lv.SetMinLevel(LogLevelWarning)
+	if text := exportString(t, lv, ExportText, ExportFiltered); text != " app | 10:01:00 | event <1>\n app | 10:03:00 | event <3>\n" {
+		t.Errorf("Only events matching the filter must be exported:\n%s", text)
+	}
+	if text := exportString(t, lv, ExportText, ExportAll); text != expected {
+		t.Errorf("All events must be exported:\n%s", text)
+	}
+}
+
+func TestLogView_ExportJSON(t *testing.T) {
+	lv := newExportLogView()
+	lines := strings.Split(strings.TrimSpace(exportString(t, lv, ExportJSON, ExportAll)), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("Expected 4 events, got %d", len(lines))
+	}
+	var event map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[1]), &event); err != nil {
+		t.Fatalf("Invalid JSON '%s': %v", lines[1], err)
+	}
+	expected := map[string]interface{}{
+		"id":        "1",
+		"source":    "app",
+		"timestamp": "2022-01-01T10:01:00Z",
+		"level":     "ERROR",
+		"message":   "event <1>",
+		"fields":    map[string]interface{}{"user": "user1"},
+	}
+	if fmt.Sprint(event) != fmt.Sprint(expected) {
+		t.Errorf("Invalid JSON event %v", event)
+	}
+}
+
+func TestLogView_ExportCSV(t *testing.T) {
+	lv := newExportLogView()
+	lv.AppendEvent(&LogEvent{EventID: "4", Message: "quoted, \"message\"", Fields: map[string]string{"host": "local"}})
+
+	records, err := csv.NewReader(strings.NewReader(exportString(t, lv, ExportCSV, ExportAll))).ReadAll()
+	if err != nil {
+		t.Fatalf("Invalid CSV: %v", err)
+	}
+	if len(records) != 6 || strings.Join(records[0], ",") != "id,source,timestamp,level,message,host,user" {
+		t.Fatalf("Invalid CSV header: %v", records)
+	}
+	if strings.Join(records[1], ",") != "0,app,2022-01-01T10:00:00Z,INFO,event <0>,,user0" {
+		t.Errorf("Invalid CSV record: %v", records[1])
+	}
+	if records[5][4] != "quoted, \"message\"" || records[5][5] != "local" || records[5][6] != "" {
+		t.Errorf("Invalid CSV record: %v", records[5])
+	}
+}
+
+func TestLogView_ExportHTML(t *testing.T) {
+	lv := newExportLogView()
+	lv.SetHighlightPattern(`(?P<red__bold>user)`)
+	lv.AppendEvent(NewLogEvent("4", "user logged in"))
+	lv.SetLevelHighlighting(true)
+	lv.RefreshHighlights()
+
+	page := exportString(t, lv, ExportHTML, ExportAll)
+	if !strings.HasPrefix(page, "<!DOCTYPE html>") || !strings.HasSuffix(page, "</pre>\n</body>\n</html>\n") {
+		t.Errorf("Invalid HTML document:\n%s", page)
+	}
+	if !strings.Contains(page, "event &lt;0&gt;") {
+		t.Errorf("Message must be escaped:\n%s", page)
+	}
+	if !strings.Contains(page, fmt.Sprintf("color:#%06x;", tcell.ColorRed.Hex())) ||
+		!strings.Contains(page, "font-weight:bold\">user</span>") || strings.Contains(page, "logged in </span>") {
+		t.Errorf("Highlighting must be kept:\n%s", page)
+	}
+	_, errorBg := lv.GetLevelColors(LogLevelError)
+	if !strings.Contains(page, fmt.Sprintf("background-color:#%06x\">event &lt;1&gt;</span>", errorBg.Hex())) {
+		t.Errorf("Level colors must be kept:\n%s", page)
+	}
+}
+
+func TestLogView_ExportSelection(t *testing.T) {
+	lv := newExportLogView()
+	lv.pageHeight = 5
+	lv.ScrollToEventID("1")
+	if text := exportString(t, lv, ExportText, ExportSelected); text != " app | 10:01:00 | event <1>\n" {
+		t.Errorf("Current event must be exported without selection:\n%s", text)
+	}
+	lv.ExtendSelectionDown()
+	lv.ExtendSelectionDown()
+	if text := exportString(t, lv, ExportText, ExportSelected); text != " app | 10:01:00 | event <1>\n app | 10:02:00 | event <2>\n app | 10:03:00 | event <3>\n" {
+		t.Errorf("Selected events must be exported:\n%s", text)
+	}
+
+	var buf bytes.Buffer
+	from := time.Date(2022, 1, 1, 10, 1, 0, 0, time.UTC)
+	if err := lv.ExportTimeRange(&buf, ExportText, from, from.Add(2*time.Minute)); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if buf.String() != " app | 10:01:00 | event <1>\n app | 10:02:00 | event <2>\n" {
+		t.Errorf("Events in time range must be exported:\n%s", buf.String())
+	}
+
+	if err := lv.Export(&buf, ExportFormat(42), ExportAll); err == nil {
+		t.Errorf("Unknown format must be rejected")
+	}
+}
+
+func TestLogView_ExportSpilled(t *testing.T) {
+	lv := newSpillingLogView(t, 10, 50)
+	lv.SetFilterRegex("event [0-9]$")
+
+	lines := strings.Split(strings.TrimSpace(exportString(t, lv, ExportText, ExportFiltered)), "\n")
+	if len(lines) != 10 || lines[0] != "event 0" || lines[9] != "event 9" {
+		t.Errorf("Filtered spilled events must be exported: %v", lines)
+	}
+	lines = strings.Split(strings.TrimSpace(exportString(t, lv, ExportText, ExportAll)), "\n")
+	if len(lines) != 50 || lines[0] != "event 0" || lines[49] != "event 49" {
+		t.Errorf("All spilled events must be exported: %d lines", len(lines))
+	}
+
+	lv.ScrollToTop() // page in the oldest events, newest are kept in the spill file
+	lines = strings.Split(strings.TrimSpace(exportString(t, lv, ExportText, ExportAll)), "\n")
+	if len(lines) != 50 || lines[0] != "event 0" || lines[49] != "event 49" {
+		t.Errorf("All events must be exported while paged in: %d lines", len(lines))
+	}
+}
+
+// appendingWriter appends an event to the log view on every write
+type appendingWriter struct {
+	bytes.Buffer
+	lv *LogView
+}
+
+func (w *appendingWriter) Write(p []byte) (int, error) {
+	w.lv.AppendEvent(NewLogEvent("new", "appended while exporting"))
+	return w.Buffer.Write(p)
+}
+
+func TestLogView_ExportStreaming(t *testing.T) {
+	count := exportChunkSize*2 + 10
+	lv := newSpillingLogView(t, 10, count)
+
+	// log view is not locked while the events are written, events appended during the export are not exported
+	w := &appendingWriter{lv: lv}
+	if err := lv.Export(w, ExportText, ExportAll); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(w.String()), "\n")
+	if len(lines) != count || lines[0] != "event 0" || lines[count-1] != fmt.Sprintf("event %d", count-1) {
+		t.Errorf("All events must be exported in order: %d lines", len(lines))
+	}
+	if lv.GetSpilledEventCount() <= uint(count-10) {
+		t.Errorf("Events must be appended during the export")
+	}
+}
+
+// failingWriter fails every write
+type failingWriter struct {
+	writes int
+}
+
+func (w *failingWriter) Write([]byte) (int, error) {
+	w.writes++
+	return 0, errors.New("disk full")
+}
+
+func TestLogView_ExportWriteError(t *testing.T) {
+	lv := NewLogView()
+	for i := 0; i < 200; i++ {
+		lv.AppendEvent(NewLogEvent(fmt.Sprint(i), strings.Repeat("event ", 10)))
+	}
+	for _, format := range []ExportFormat{ExportText, ExportJSON, ExportCSV, ExportHTML} {
+		w := &failingWriter{}
+		if err := lv.Export(w, format, ExportAll); err == nil || err.Error() != "disk full" {
+			t.Errorf("Format %v: expected write error, got %v", format, err)
+		}
+		if w.writes != 1 {
+			t.Errorf("Format %v: export must stop after the first error, %d writes", format, w.writes)
+		}
+	}
+}
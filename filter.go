@@ -0,0 +1,245 @@
+package logview
+
+import (
+	"regexp"
+)
+
+// SetFilter sets a predicate that decides which events are displayed in the log view.
+//
+// Events that do not match the filter are hidden, but not removed from the log view, so changing or clearing the filter
+// will bring them back. Hidden events are not drawn, are skipped during scrolling and are not counted by
+// GetVisibleEventCount. Setting filter to nil displays all the events.
+func (lv *LogView) SetFilter(filter func(event *LogEvent) bool) {
+	defer lv.fireOnCurrentChange(lv.current)
+	lv.Lock()
+	defer lv.Unlock()
+
+	lv.filter = filter
+	lv.refilterLines()
+}
+
+// SetFilterRegex sets the filter that displays only events with a message matching given regular expression.
+//
+// Empty regular expression clears the filter. Returns error if the regular expression cannot be compiled, the filter
+// is not changed in that case.
+func (lv *LogView) SetFilterRegex(regex string) error {
+	if regex == "" {
+		lv.SetFilter(nil)
+		return nil
+	}
+	matcher, err := regexp.Compile(regex)
+	if err != nil {
+		return err
+	}
+	lv.SetFilter(func(event *LogEvent) bool {
+		return matcher.MatchString(event.Message)
+	})
+	return nil
+}
+
+// ClearFilter removes the filter, all the events will be displayed
+func (lv *LogView) ClearFilter() {
+	lv.SetFilter(nil)
+}
+
+// IsFiltered returns true if events can be hidden, i.e. the filter is set or the minimum level is above trace
+func (lv *LogView) IsFiltered() bool {
+	lv.RLock()
+	defer lv.RUnlock()
+
+	return lv.filter != nil || lv.minLevel.Severity() > LogLevelTrace.Severity()
+}
+
+// SetMinLevel hides events with severity lower than the given level. Events are not removed from the log view and
+// will be displayed again when the threshold is lowered. LogLevelAll displays events of all levels, which is default.
+//
+// Minimum level works together with the filter set by SetFilter, event is displayed only if it passes both.
+func (lv *LogView) SetMinLevel(level LogLevel) {
+	defer lv.fireOnCurrentChange(lv.current)
+	lv.Lock()
+	defer lv.Unlock()
+
+	lv.minLevel = level
+	lv.refilterLines()
+}
+
+// GetMinLevel returns the minimum level of displayed events
+func (lv *LogView) GetMinLevel() LogLevel {
+	lv.RLock()
+	defer lv.RUnlock()
+
+	return lv.minLevel
+}
+
+// CycleMinLevel raises the minimum level of displayed events to the next severity level. After LogLevelFatal
+// the minimum level is reset to LogLevelAll. Returns the new minimum level.
+func (lv *LogView) CycleMinLevel() LogLevel {
+	defer lv.fireOnCurrentChange(lv.current)
+	lv.Lock()
+	defer lv.Unlock()
+
+	lv.cycleMinLevel()
+	return lv.minLevel
+}
+
+// GetVisibleEventCount returns number of events that are not hidden by the filter
+func (lv *LogView) GetVisibleEventCount() uint {
+	lv.RLock()
+	defer lv.RUnlock()
+
+	return lv.visibleCount
+}
+
+// *******************************
+// internal implementation details
+
+func (lv *LogView) matchesFilter(event *logEventLine) bool {
+	if event.Level.Severity() < lv.minLevel.Severity() {
+		return false
+	}
+	return lv.filter == nil || lv.filter(event.AsLogEvent())
+}
+
+// cycleMinLevel sets minimum level to the next severity level. Trace level is skipped as it is the same as showing
+// all the levels
+func (lv *LogView) cycleMinLevel() {
+	severity := lv.minLevel.Severity()
+	if severity == 0 {
+		severity = LogLevelTrace.Severity()
+	}
+	if severity < len(LogLevels) {
+		lv.minLevel = LogLevels[severity]
+	} else {
+		lv.minLevel = LogLevelAll
+	}
+	lv.refilterLines()
+}
+
+// setFilteredOut changes visibility of the event that is already in the log view and updates visible event count.
+// event must be unwrapped
+func (lv *LogView) setFilteredOut(event *logEventLine, filteredOut bool) {
+	if event.filteredOut == filteredOut {
+		return
+	}
+	event.filteredOut = filteredOut
+	isMatched := len(event.searchMatches) > 0
+	if filteredOut {
+		lv.visibleCount--
+		if isMatched {
+			lv.searchMatchCount--
+		}
+		if lv.top == event {
+			lv.top = lv.nearestVisible(event)
+		}
+		if lv.current == event {
+			lv.current = lv.nearestVisible(event)
+		}
+	} else {
+		lv.visibleCount++
+		if isMatched {
+			lv.searchMatchCount++
+		}
+		if lv.top == nil {
+			lv.top = event
+			lv.current = event
+		}
+	}
+}
+
+// refilterLines applies filter to all the events. Events that change visibility are re-wrapped
+func (lv *LogView) refilterLines() {
+	lv.visibleCount = 0
+	lv.searchMatchCount = 0
+	event := lv.firstEvent
+	for event != nil {
+		filteredOut := !lv.matchesFilter(event)
+		if event.filteredOut != filteredOut {
+			event = lv.mergeWrappedLines(event)
+			event.filteredOut = filteredOut
+			event = lv.calculateWrap(event)
+			lv.updateMemoryUsage(event)
+		} else {
+			event = findLastWrappedLine(event)
+		}
+		if !filteredOut {
+			lv.visibleCount++
+			if len(event.searchMatches) > 0 {
+				lv.searchMatchCount++
+			}
+		}
+		event = event.next
+	}
+	lv.ensureVisiblePosition()
+}
+
+// ensureVisiblePosition moves top and current lines to the nearest visible lines if they were hidden
+func (lv *LogView) ensureVisiblePosition() {
+	if lv.following {
+		lv.scrollToEnd()
+		return
+	}
+	lv.current = lv.nearestVisible(lv.current)
+	lv.top = lv.nearestVisible(lv.top)
+	if lv.current != nil && lv.distance(lv.current, lv.top) >= lv.pageHeight {
+		lv.top = lv.current
+	}
+}
+
+// nextVisible returns the next line that is not hidden by the filter or nil if there is none
+func (lv *LogView) nextVisible(event *logEventLine) *logEventLine {
+	if event == nil {
+		return nil
+	}
+	event = event.next
+	for event != nil && event.filteredOut {
+		event = event.next
+	}
+	return event
+}
+
+// prevVisible returns the previous line that is not hidden by the filter or nil if there is none
+func (lv *LogView) prevVisible(event *logEventLine) *logEventLine {
+	if event == nil {
+		return nil
+	}
+	event = event.previous
+	for event != nil && event.filteredOut {
+		event = event.previous
+	}
+	return event
+}
+
+func (lv *LogView) firstVisible() *logEventLine {
+	if lv.firstEvent == nil || !lv.firstEvent.filteredOut {
+		return lv.firstEvent
+	}
+	return lv.nextVisible(lv.firstEvent)
+}
+
+func (lv *LogView) lastVisible() *logEventLine {
+	if lv.lastEvent == nil || !lv.lastEvent.filteredOut {
+		return lv.lastEvent
+	}
+	return lv.prevVisible(lv.lastEvent)
+}
+
+// nearestVisible returns the event itself if it is visible, otherwise the closest visible line after it or, if there
+// are none, the closest visible line before it. If event is nil, the first visible line is returned
+func (lv *LogView) nearestVisible(event *logEventLine) *logEventLine {
+	if event == nil {
+		return lv.firstVisible()
+	}
+	if !event.filteredOut {
+		return event
+	}
+	return lv.visibleNeighbour(event)
+}
+
+// visibleNeighbour returns the closest visible line after the event or, if there are none, the closest visible line
+// before it
+func (lv *LogView) visibleNeighbour(event *logEventLine) *logEventLine {
+	if next := lv.nextVisible(event); next != nil {
+		return next
+	}
+	return lv.prevVisible(event)
+}
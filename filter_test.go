@@ -0,0 +1,169 @@
+package logview
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func screenLine(screen tcell.SimulationScreen, y int) string {
+	cells, width, _ := screen.GetContents()
+	var sb strings.Builder
+	for x := 0; x < width; x++ {
+		sb.WriteString(string(cells[y*width+x].Runes))
+	}
+	return strings.TrimRight(sb.String(), " ")
+}
+
+func TestLogView_SetFilter(t *testing.T) {
+	screen := tcell.NewSimulationScreen("UTF-8")
+	screen.Init()
+	screen.SetSize(40, 5)
+	lv := NewLogView()
+	lv.SetRect(0, 0, 40, 5)
+	lv.SetHighlightCurrentEvent(true)
+	ts := time.Now().Add(-24 * time.Hour)
+	lv.AppendEvents(randomEvents(100, ts))
+
+	lv.SetFilter(func(event *LogEvent) bool {
+		return strings.HasSuffix(event.Message, "0")
+	})
+	lv.Draw(screen)
+	screen.Show()
+
+	if lv.GetEventCount() != 100 || lv.GetVisibleEventCount() != 10 {
+		t.Errorf("Invalid event counts, total=%d, visible=%d", lv.GetEventCount(), lv.GetVisibleEventCount())
+	}
+	if lv.GetCurrentEvent().EventID != "e90" || lv.top.EventID != "e50" {
+		t.Errorf("Should follow the last visible event, current=%s, top=%s", lv.GetCurrentEvent().EventID, lv.top.EventID)
+	}
+	if screenLine(screen, 0) != "Event #50" || screenLine(screen, 4) != "Event #90" {
+		t.Errorf("Invalid filtered lines drawn: '%s', '%s'", screenLine(screen, 0), screenLine(screen, 4))
+	}
+
+	lv.ScrollToTop()
+	lv.SelectNextEvent()
+	if lv.GetCurrentEvent().EventID != "e10" {
+		t.Errorf("Scrolling should skip hidden events, current=%s", lv.GetCurrentEvent().EventID)
+	}
+
+	lv.ClearFilter()
+	if lv.GetVisibleEventCount() != 100 || lv.GetCurrentEvent().EventID != "e10" {
+		t.Errorf("Clearing filter should show all events and keep current, visible=%d, current=%s",
+			lv.GetVisibleEventCount(), lv.GetCurrentEvent().EventID)
+	}
+}
+
+func TestLogView_SetFilterRegex(t *testing.T) {
+	lv := NewLogView()
+	lv.SetHighlightCurrentEvent(true)
+	ts := time.Now().Add(-24 * time.Hour)
+	lv.AppendEvents(randomEvents(100, ts))
+
+	if err := lv.SetFilterRegex(`#[1-3]$`); err != nil {
+		t.Fatalf("Failed to set filter: %v", err)
+	}
+	if lv.GetVisibleEventCount() != 3 {
+		t.Errorf("Expected 3 visible events, got %d", lv.GetVisibleEventCount())
+	}
+	if err := lv.SetFilterRegex(`(`); err == nil {
+		t.Errorf("Expected error for invalid regular expression")
+	}
+	if !lv.IsFiltered() || lv.GetVisibleEventCount() != 3 {
+		t.Errorf("Invalid regular expression should not change the filter")
+	}
+
+	if lv.ScrollToEventID("e20") {
+		t.Errorf("Should not scroll to hidden event")
+	}
+	if !lv.ScrollToTimestamp(ts.Add(2*time.Second)) || lv.GetCurrentEvent().EventID != "e2" {
+		t.Errorf("Failed to scroll to timestamp in filtered view")
+	}
+	if lv.ScrollToTimestamp(ts.Add(4*time.Second)) || lv.GetCurrentEvent().EventID != "e2" {
+		t.Errorf("Should not scroll to hidden events")
+	}
+}
+
+func TestLogView_FilterAppend(t *testing.T) {
+	lv := NewLogView()
+	lv.SetHighlightCurrentEvent(true)
+	lv.SetFilter(func(event *LogEvent) bool {
+		return event.Level == LogLevelError
+	})
+
+	lv.AppendEvent(NewLogEvent("1", "info"))
+	if lv.GetCurrentEvent() != nil || lv.GetVisibleEventCount() != 0 {
+		t.Errorf("There should be no visible events")
+	}
+
+	event := NewLogEvent("2", "error")
+	event.Level = LogLevelError
+	lv.AppendEvent(event)
+	lv.AppendEvent(NewLogEvent("3", "info"))
+
+	if lv.GetEventCount() != 3 || lv.GetVisibleEventCount() != 1 || lv.GetCurrentEvent().EventID != "2" {
+		t.Errorf("Only error event must be visible")
+	}
+
+	lv.SetMaxEvents(1)
+	if lv.GetVisibleEventCount() != 0 || lv.GetCurrentEvent() != nil {
+		t.Errorf("Visible event should have been evicted, visible=%d, current=%v", lv.GetVisibleEventCount(), lv.GetCurrentEvent())
+	}
+}
+
+func TestLogView_SetMinLevel(t *testing.T) {
+	lv := NewLogView()
+	lv.SetHighlightCurrentEvent(true)
+	levels := []LogLevel{LogLevelTrace, LogLevelInfo, LogLevelWarning, LogLevelDebug, LogLevelError, LogLevelFatal, LogLevelInfo}
+	for i, level := range levels {
+		event := NewLogEvent("e"+strconv.Itoa(i), level.String())
+		event.Level = level
+		lv.AppendEvent(event)
+	}
+
+	lv.SetMinLevel(LogLevelWarning)
+	if lv.GetVisibleEventCount() != 3 || lv.GetEventCount() != 7 {
+		t.Errorf("Expected 3 visible events, got %d", lv.GetVisibleEventCount())
+	}
+	if lv.GetCurrentEvent().EventID != "e5" {
+		t.Errorf("Current event should be the last visible one, got %s", lv.GetCurrentEvent().EventID)
+	}
+	lv.ScrollToTop()
+	if lv.GetCurrentEvent().EventID != "e2" {
+		t.Errorf("First visible event must be e2, got %s", lv.GetCurrentEvent().EventID)
+	}
+
+	lv.SetFilter(func(event *LogEvent) bool {
+		return event.Level != LogLevelError
+	})
+	if lv.GetVisibleEventCount() != 2 {
+		t.Errorf("Filter and min level must be combined, got %d visible events", lv.GetVisibleEventCount())
+	}
+	lv.ClearFilter()
+	if !lv.IsFiltered() {
+		t.Errorf("Log view must be filtered by min level")
+	}
+
+	event := NewLogEvent("new", "debug")
+	event.Level = LogLevelDebug
+	lv.AppendEvent(event)
+	if lv.GetVisibleEventCount() != 3 {
+		t.Errorf("Appended debug event must be hidden")
+	}
+
+	expected := []LogLevel{LogLevelError, LogLevelCritical, LogLevelFatal, LogLevelAll, LogLevelDebug}
+	for _, level := range expected {
+		if l := lv.CycleMinLevel(); l != level {
+			t.Errorf("Expected min level %v, got %v", level, l)
+		}
+	}
+	if lv.GetVisibleEventCount() != 7 {
+		t.Errorf("Only trace event should be hidden, got %d visible events", lv.GetVisibleEventCount())
+	}
+	lv.SetMinLevel(LogLevelAll)
+	if lv.IsFiltered() {
+		t.Errorf("Log view must not be filtered")
+	}
+}
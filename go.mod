@@ -4,16 +4,18 @@ go 1.20
 
 require (
 	code.rocketnine.space/tslocum/cbind v0.1.5
+	github.com/BurntSushi/toml v1.4.0
 	github.com/araddon/dateparse v0.0.0-20210429162001-6b43995a97de
 	github.com/dlclark/regexp2 v1.11.4
 	github.com/gdamore/tcell/v2 v2.8.1
+	github.com/mattn/go-runewidth v0.0.16
 	github.com/rivo/tview v0.0.0-20241227133733-17b7edb88c57
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
 	github.com/gdamore/encoding v1.0.1 // indirect
 	github.com/lucasb-eyer/go-colorful v1.2.0 // indirect
-	github.com/mattn/go-runewidth v0.0.16 // indirect
 	github.com/rivo/uniseg v0.4.7 // indirect
 	golang.org/x/sys v0.29.0 // indirect
 	golang.org/x/term v0.28.0 // indirect
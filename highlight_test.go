@@ -94,3 +94,123 @@ func TestLogView_colorizeDraw(t *testing.T) {
 
 	lv.drawEvent(screen, 0, 0, event)
 }
+
+func TestLogView_colorizeLevels(t *testing.T) {
+	lv := NewLogView()
+	lv.SetLevelHighlighting(true)
+	lv.SetLevelFgColor(LogLevelDebug, tcell.ColorGreen)
+	lv.SetLevelBgColor(LogLevelFatal, tcell.ColorBlue)
+	lv.SetHighlightPattern(`(?P<red>never matches)`)
+
+	event := &logEventLine{Runes: []rune("debug"), Level: LogLevelDebug}
+	lv.colorize(event)
+	if fg, _, _ := event.styleSpans[0].style.Decompose(); fg != tcell.ColorGreen {
+		t.Errorf("Debug event must have green text, got %v", fg)
+	}
+
+	event = &logEventLine{Runes: []rune("fatal"), Level: LogLevelFatal}
+	lv.colorize(event)
+	if _, bg, _ := event.styleSpans[0].style.Decompose(); bg != tcell.ColorBlue {
+		t.Errorf("Fatal event must have blue background, got %v", bg)
+	}
+}
+
+func TestLogView_HighlightRules(t *testing.T) {
+	lv := NewLogView()
+	red := tcell.StyleDefault.Foreground(tcell.ColorRed)
+	green := tcell.StyleDefault.Foreground(tcell.ColorGreen)
+	blue := tcell.StyleDefault.Foreground(tcell.ColorBlue)
+	if err := lv.AddHighlightRule("error", `error \w+`, red); err != nil {
+		t.Fatalf("Failed to add rule: %v", err)
+	}
+	_ = lv.AddHighlightRule("word", `\w+`, green)
+	_ = lv.AddHighlightRule("number", `\d+`, blue)
+	if err := lv.AddHighlightRule("broken", `(`, blue); err == nil {
+		t.Errorf("Invalid pattern must be reported")
+	}
+
+	//                                     0         1
+	//                                     0123456789012345
+	event := &logEventLine{Runes: []rune("error code 42 x")}
+	spanText := func(span styledSpan) string {
+		return string(event.Runes[span.start:minInt(span.end, len(event.Runes))])
+	}
+	colorize := func() map[string]tcell.Color {
+		lv.colorize(event)
+		colors := make(map[string]tcell.Color)
+		for _, span := range event.styleSpans {
+			fg, _, _ := span.style.Decompose()
+			colors[spanText(span)] = fg
+		}
+		return colors
+	}
+
+	// earlier added rule wins over the later ones with the same priority
+	colors := colorize()
+	if colors["error code"] != tcell.ColorRed || colors["42"] != tcell.ColorGreen || colors["x"] != tcell.ColorGreen {
+		t.Errorf("Invalid highlighting: %v", colors)
+	}
+
+	lv.SetHighlightRulePriority("number", 1)
+	if rules := lv.GetHighlightRules(); rules[0] != "number" || rules[1] != "error" || rules[2] != "word" {
+		t.Errorf("Invalid rule order: %v", rules)
+	}
+	colors = colorize()
+	if colors["42"] != tcell.ColorBlue {
+		t.Errorf("Higher priority rule should win: %v", colors)
+	}
+
+	lv.SetHighlightRuleEnabled("error", false)
+	colors = colorize()
+	if lv.IsHighlightRuleEnabled("error") || colors["error"] != tcell.ColorGreen || colors["code"] != tcell.ColorGreen {
+		t.Errorf("Disabled rule should not be applied: %v", colors)
+	}
+
+	lv.RemoveHighlightRule("word")
+	lv.RemoveHighlightRule("number")
+	colors = colorize()
+	if len(event.styleSpans) != 1 || len(lv.GetHighlightRules()) != 1 {
+		t.Errorf("Removed rules should not be applied: %v", colors)
+	}
+}
+
+func TestParseStyleName(t *testing.T) {
+	base := tcell.StyleDefault
+	tests := []struct {
+		name     string
+		expected tcell.Style
+	}{
+		{"red", base.Foreground(tcell.ColorRed)},
+		{"white_lightsalmon", base.Foreground(tcell.ColorWhite).Background(tcell.ColorLightSalmon)},
+		{"#ff8800", base.Foreground(tcell.NewHexColor(0xff8800))},
+		{"x00ff00_color208", base.Foreground(tcell.NewHexColor(0x00ff00)).Background(tcell.PaletteColor(208))},
+		{"red__bold_underline", base.Foreground(tcell.ColorRed).Bold(true).Underline(true)},
+		{"_blue__italic", base.Background(tcell.ColorBlue).Italic(true)},
+		{"208_red", base.Background(tcell.ColorRed)},
+		{"unknown__nothing", base},
+	}
+	for _, test := range tests {
+		if style := parseStyleName(test.name, base); style != test.expected {
+			t.Errorf("Invalid style for %s: %v, expected %v", test.name, style, test.expected)
+		}
+	}
+}
+
+func TestLogView_RegisterHighlightStyle(t *testing.T) {
+	lv := NewLogView()
+	lv.SetHighlightPattern(`(?P<x0000ff__bold>\d+) (?P<Failure>failed)`)
+	failure := tcell.StyleDefault.Foreground(tcell.ColorYellow).Reverse(true)
+	lv.RegisterHighlightStyle("failure", failure)
+
+	event := &logEventLine{Runes: []rune("42 failed")}
+	lv.colorize(event)
+	if len(event.styleSpans) != 3 {
+		t.Fatalf("Invalid spans: %+v", event.styleSpans)
+	}
+	if fg, _, attrs := event.styleSpans[0].style.Decompose(); fg != tcell.NewHexColor(0x0000ff) || attrs&tcell.AttrBold == 0 {
+		t.Errorf("Hex color and bold attribute expected, got %v %v", fg, attrs)
+	}
+	if event.styleSpans[2].style != failure {
+		t.Errorf("Registered style expected, got %v", event.styleSpans[2].style)
+	}
+}
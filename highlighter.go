@@ -9,14 +9,28 @@ import (
 	"strings"
 )
 
-func (lv *LogView) defaultStyleEvent(event *logEventLine) *logEventLine {
-	event.styleSpans = []styledSpan{
-		{
-			start: 0,
-			end:   len(event.Runes),
-			style: lv.defaultStyle,
-		},
+// levelColors defines text and background colors for events of a certain level.
+// tcell.ColorDefault means the color of the default style is used
+type levelColors struct {
+	fg tcell.Color
+	bg tcell.Color
+}
+
+func defaultLevelColors() map[LogLevel]levelColors {
+	return map[LogLevel]levelColors{
+		LogLevelTrace:    {fg: tcell.ColorGray, bg: tcell.ColorDefault},
+		LogLevelDebug:    {fg: tcell.ColorSilver, bg: tcell.ColorDefault},
+		LogLevelInfo:     {fg: tcell.ColorDefault, bg: tcell.ColorDefault},
+		LogLevelNotice:   {fg: tcell.ColorLightSkyBlue, bg: tcell.ColorDefault},
+		LogLevelWarning:  {fg: tcell.ColorDefault, bg: tcell.ColorSaddleBrown},
+		LogLevelError:    {fg: tcell.ColorDefault, bg: tcell.ColorIndianRed},
+		LogLevelCritical: {fg: tcell.ColorWhite, bg: tcell.ColorFireBrick},
+		LogLevelFatal:    {fg: tcell.ColorYellow, bg: tcell.ColorDarkRed},
 	}
+}
+
+func (lv *LogView) defaultStyleEvent(event *logEventLine, style tcell.Style) *logEventLine {
+	event.styleSpans = plainSpans(event.ansiSpans, 0, len(event.Runes), style)
 	return event
 }
 
@@ -32,6 +46,9 @@ func (cg captureGroupSorter) Len() int {
 }
 
 func (cg captureGroupSorter) Less(i, j int) bool {
+	if cg[i].Index == cg[j].Index {
+		return cg[i].Length > cg[j].Length
+	}
 	return cg[i].Index < cg[j].Index
 }
 
@@ -44,108 +61,174 @@ func isInt(text string) bool {
 	return err == nil
 }
 
+// highlight is a part of the message highlighted by the highlight pattern group or by the highlight rule
+type highlight struct {
+	start  int
+	length int
+	style  tcell.Style
+}
+
 func (lv *LogView) colorize(event *logEventLine) *logEventLine {
 	if event.order != 0 {
 		panic(fmt.Errorf("cannot colorize wrapped line"))
 	}
+	event.colorGeneration = lv.colorGeneration
 	defaultStyle := lv.defaultStyle
 	useSpecialBg := false
-	if lv.highlightLevels && event.Level != LogLevelInfo {
-		useSpecialBg = true
-		if event.Level == LogLevelWarning {
-			defaultStyle = defaultStyle.Background(lv.warningBgColor)
-		} else {
-			defaultStyle = defaultStyle.Background(lv.errorBgColor)
+	if colors, ok := lv.levelColors[event.Level]; ok && lv.highlightLevels {
+		if colors.fg != tcell.ColorDefault {
+			defaultStyle = defaultStyle.Foreground(colors.fg)
 		}
-	}
-	if lv.highlightingEnabled && lv.highlightPattern != nil {
-		text := event.message()
-		match, err := lv.highlightPattern.FindStringMatch(text)
-		if err != nil || match == nil {
-			return lv.defaultStyleEvent(event)
+		if colors.bg != tcell.ColorDefault {
+			useSpecialBg = true
+			defaultStyle = defaultStyle.Background(colors.bg)
 		}
-		groups := make([]captureGroup, 0)
-		for match != nil {
-			for _, gr := range match.Groups() {
-				if len(gr.Captures) > 0 && !isInt(gr.Name) {
-					groups = append(groups, captureGroup{
-						Capture: gr.Capture,
-						name:    gr.Name,
-					})
-				}
-			}
-			match, err = lv.highlightPattern.FindNextMatch(match)
-			if err != nil {
-				return lv.defaultStyleEvent(event)
+	}
+	if !lv.highlightingEnabled || (lv.highlightPattern == nil && len(lv.highlightRules) == 0) {
+		return lv.defaultStyleEvent(event, defaultStyle)
+	}
+	text := []rune(event.message())
+	highlights := append(lv.patternHighlights(event.message(), defaultStyle, useSpecialBg),
+		lv.ruleHighlights(event.message(), defaultStyle)...)
+	if len(highlights) == 0 {
+		return lv.defaultStyleEvent(event, defaultStyle)
+	}
+	event.styleSpans = buildSpans(text, highlights, event.ansiSpans, defaultStyle)
+	return event
+}
+
+// patternHighlights finds the named groups of the highlight pattern in the text. Groups are ordered by position,
+// nested groups follow the enclosing ones
+func (lv *LogView) patternHighlights(text string, defaultStyle tcell.Style, useSpecialBg bool) []highlight {
+	if lv.highlightPattern == nil {
+		return nil
+	}
+	match, err := lv.highlightPattern.FindStringMatch(text)
+	if err != nil || match == nil {
+		return nil
+	}
+	groups := make([]captureGroup, 0)
+	for match != nil {
+		for _, gr := range match.Groups() {
+			if len(gr.Captures) > 0 && !isInt(gr.Name) {
+				groups = append(groups, captureGroup{
+					Capture: gr.Capture,
+					name:    gr.Name,
+				})
 			}
 		}
-		sort.Sort(captureGroupSorter(groups))
-		event.styleSpans = lv.buildSpans([]rune(text), groups, defaultStyle, useSpecialBg)
-	} else {
-		event.styleSpans = []styledSpan{
-			{
-				start: 0,
-				end:   len(event.Runes),
-				style: defaultStyle,
-			},
+		match, err = lv.highlightPattern.FindNextMatch(match)
+		if err != nil {
+			return nil
 		}
 	}
-	return event
+	sort.Stable(captureGroupSorter(groups))
+
+	_, dbg, _ := defaultStyle.Decompose()
+	highlights := make([]highlight, len(groups))
+	for i, group := range groups {
+		style := lv.groupNameToStyle(group.name)
+		if useSpecialBg {
+			style = style.Background(dbg)
+		}
+		highlights[i] = highlight{start: group.Index, length: group.Length, style: style}
+	}
+	return highlights
 }
 
-func (lv *LogView) groupNameToStyle(colorName string) tcell.Style {
-	style := lv.defaultStyle
-	colorPair := strings.Split(strings.ToLower(colorName), "_")
-	if fg, ok := tcell.ColorNames[colorPair[0]]; ok {
+func (lv *LogView) groupNameToStyle(groupName string) tcell.Style {
+	name := strings.ToLower(groupName)
+	if style, ok := lv.highlightStyles[name]; ok {
+		return style
+	}
+	return parseStyleName(name, lv.defaultStyle)
+}
+
+// styleAttributes are the attribute names that can be used in highlight group names
+var styleAttributes = map[string]tcell.AttrMask{
+	"bold":          tcell.AttrBold,
+	"dim":           tcell.AttrDim,
+	"italic":        tcell.AttrItalic,
+	"underline":     tcell.AttrUnderline,
+	"blink":         tcell.AttrBlink,
+	"reverse":       tcell.AttrReverse,
+	"strikethrough": tcell.AttrStrikeThrough,
+}
+
+// parseStyleName converts style name in the form of foreground_background__attribute_attribute to a style based on
+// the given style. All parts are optional, unknown colors and attributes are ignored
+func parseStyleName(name string, style tcell.Style) tcell.Style {
+	colors, attrs := name, ""
+	if i := strings.Index(name, "__"); i >= 0 {
+		colors, attrs = name[:i], name[i+2:]
+	}
+	colorPair := strings.Split(colors, "_")
+	if fg, ok := parseColorName(colorPair[0]); ok {
 		style = style.Foreground(fg)
 	}
 	if len(colorPair) > 1 {
-		if bg, ok := tcell.ColorNames[colorPair[1]]; ok {
+		if bg, ok := parseColorName(colorPair[1]); ok {
 			style = style.Background(bg)
 		}
 	}
+	if attrs != "" {
+		var mask tcell.AttrMask
+		for _, attr := range strings.Split(attrs, "_") {
+			mask |= styleAttributes[attr]
+		}
+		style = addAttributes(style, mask)
+	}
 	return style
 }
 
-func (lv *LogView) buildSpans(text []rune, groups []captureGroup, defaultStyle tcell.Style, useDefaultBg bool) []styledSpan {
-	currentPos := 0
-	spans := make([]styledSpan, 0)
-
-	_, dbg, _ := defaultStyle.Decompose()
-
-	for _, group := range groups {
-		if group.Index != currentPos {
-			runeLen := len(text[currentPos:group.Index])
-			beforeSpan := styledSpan{
-				start: currentPos,
-				end:   currentPos + runeLen,
-				style: defaultStyle,
-			}
-			spans = append(spans, beforeSpan)
-			currentPos = currentPos + runeLen
+// parseColorName converts color name to color. Color can be one of the web color names, hex RGB value
+// written as #rrggbb or xrrggbb, or an index in 256-color palette written as colorN
+func parseColorName(name string) (tcell.Color, bool) {
+	if color, ok := tcell.ColorNames[name]; ok {
+		return color, true
+	}
+	if len(name) == 7 && (name[0] == '#' || name[0] == 'x') {
+		if rgb, err := strconv.ParseUint(name[1:], 16, 32); err == nil {
+			return tcell.NewHexColor(int32(rgb)), true
 		}
+	}
+	if strings.HasPrefix(name, "color") {
+		if index, err := strconv.Atoi(name[len("color"):]); err == nil && index >= 0 && index < 256 {
+			return tcell.PaletteColor(index), true
+		}
+	}
+	return tcell.ColorDefault, false
+}
 
-		style := lv.groupNameToStyle(group.name)
-
-		if useDefaultBg {
-			style = style.Background(dbg)
+// buildSpans splits the text into styled spans. Highlights that come later in the list are drawn over the earlier
+// ones when they overlap. Parts of the text that are not highlighted use the default style with ANSI colors applied
+func buildSpans(text []rune, highlights []highlight, ansi []ansiSpan, defaultStyle tcell.Style) []styledSpan {
+	owners := make([]int, len(text))
+	for i := range owners {
+		owners[i] = -1
+	}
+	for i, h := range highlights {
+		for pos := h.start; pos < h.start+h.length && pos < len(text); pos++ {
+			owners[pos] = i
 		}
+	}
 
-		matched := styledSpan{
-			start: currentPos,
-			end:   currentPos + group.Length,
-			style: style,
+	spans := make([]styledSpan, 0)
+	start := 0
+	for start < len(text) {
+		end := start + 1
+		for end < len(text) && owners[end] == owners[start] {
+			end++
 		}
-		spans = append(spans, matched)
-		currentPos += group.Length
-	}
-	if currentPos < len(text) {
-		afterSpan := styledSpan{
-			start: currentPos,
-			end:   len(text) + 1,
-			style: defaultStyle,
+		if owners[start] < 0 {
+			if end == len(text) {
+				end++ // plain span at the end of the text covers the position after the last character
+			}
+			spans = append(spans, plainSpans(ansi, start, end, defaultStyle)...)
+		} else {
+			spans = append(spans, styledSpan{start: start, end: end, style: highlights[owners[start]].style})
 		}
-		spans = append(spans, afterSpan)
+		start = end
 	}
 	return spans
 }
@@ -0,0 +1,178 @@
+package logview
+
+import (
+	"github.com/dlclark/regexp2"
+	"github.com/gdamore/tcell/v2"
+	"sort"
+	"strings"
+)
+
+// highlightRule highlights all matches of the pattern with a style
+type highlightRule struct {
+	name     string
+	pattern  *regexp2.Regexp
+	style    tcell.Style
+	priority int
+	enabled  bool
+	added    int
+}
+
+// AddHighlightRule adds a rule highlighting all the matches of the regular expression pattern with a given style.
+// Pattern is case-insensitive. Colors that are not set in the style are taken from the event style, i.e. level
+// background color is kept if the style only sets foreground color.
+//
+// Rules are applied on top of the highlight pattern set with SetHighlightPattern. When matches of several rules
+// overlap, the rule with the highest priority wins, rules with the same priority win in the order they were added.
+// If the rule with the same name already exists, its pattern and style are replaced.
+//
+// Rules are applied to the events when they are appended. Call RefreshHighlights to apply rule changes to all
+// the events in the log view.
+func (lv *LogView) AddHighlightRule(name string, pattern string, style tcell.Style) error {
+	re, err := regexp2.Compile(pattern, regexp2.IgnoreCase+regexp2.RE2)
+	if err != nil {
+		return err
+	}
+
+	lv.Lock()
+	defer lv.Unlock()
+
+	if rule := lv.findHighlightRule(name); rule != nil {
+		rule.pattern = re
+		rule.style = style
+		return nil
+	}
+	lv.rulesAdded++
+	lv.highlightRules = append(lv.highlightRules, &highlightRule{
+		name:    name,
+		pattern: re,
+		style:   style,
+		enabled: true,
+		added:   lv.rulesAdded,
+	})
+	lv.sortHighlightRules()
+	return nil
+}
+
+// RemoveHighlightRule removes the highlight rule with a given name
+func (lv *LogView) RemoveHighlightRule(name string) {
+	lv.Lock()
+	defer lv.Unlock()
+
+	for i, rule := range lv.highlightRules {
+		if rule.name == name {
+			lv.highlightRules = append(lv.highlightRules[:i], lv.highlightRules[i+1:]...)
+			return
+		}
+	}
+}
+
+// SetHighlightRulePriority sets the priority of the highlight rule. Rules with higher priority win when matches
+// overlap. Default priority is 0
+func (lv *LogView) SetHighlightRulePriority(name string, priority int) {
+	lv.Lock()
+	defer lv.Unlock()
+
+	if rule := lv.findHighlightRule(name); rule != nil {
+		rule.priority = priority
+		lv.sortHighlightRules()
+	}
+}
+
+// SetHighlightRuleEnabled enables or disables the highlight rule without removing it
+func (lv *LogView) SetHighlightRuleEnabled(name string, enabled bool) {
+	lv.Lock()
+	defer lv.Unlock()
+
+	if rule := lv.findHighlightRule(name); rule != nil {
+		rule.enabled = enabled
+	}
+}
+
+// IsHighlightRuleEnabled returns whether the highlight rule exists and is enabled
+func (lv *LogView) IsHighlightRuleEnabled(name string) bool {
+	lv.RLock()
+	defer lv.RUnlock()
+
+	rule := lv.findHighlightRule(name)
+	return rule != nil && rule.enabled
+}
+
+// GetHighlightRules returns names of all the highlight rules, from the highest precedence to the lowest
+func (lv *LogView) GetHighlightRules() []string {
+	lv.RLock()
+	defer lv.RUnlock()
+
+	names := make([]string, len(lv.highlightRules))
+	for i, rule := range lv.highlightRules {
+		names[i] = rule.name
+	}
+	return names
+}
+
+// RegisterHighlightStyle sets the style for highlight pattern groups with a given name. Registered styles take
+// precedence over the colors parsed from the group name. Group names are case-insensitive.
+//
+// Call RefreshHighlights to apply the style to the events that are already in the log view
+func (lv *LogView) RegisterHighlightStyle(name string, style tcell.Style) {
+	lv.Lock()
+	defer lv.Unlock()
+
+	lv.highlightStyles[strings.ToLower(name)] = style
+}
+
+// *******************************
+// internal implementation details
+
+func (lv *LogView) findHighlightRule(name string) *highlightRule {
+	for _, rule := range lv.highlightRules {
+		if rule.name == name {
+			return rule
+		}
+	}
+	return nil
+}
+
+// sortHighlightRules orders rules from the highest precedence to the lowest
+func (lv *LogView) sortHighlightRules() {
+	sort.SliceStable(lv.highlightRules, func(i, j int) bool {
+		a, b := lv.highlightRules[i], lv.highlightRules[j]
+		if a.priority != b.priority {
+			return a.priority > b.priority
+		}
+		return a.added < b.added
+	})
+}
+
+// ruleHighlights finds the matches of all enabled rules. Rules with the highest precedence come last,
+// so they are drawn over the others
+func (lv *LogView) ruleHighlights(text string, defaultStyle tcell.Style) []highlight {
+	var highlights []highlight
+	for i := len(lv.highlightRules) - 1; i >= 0; i-- {
+		rule := lv.highlightRules[i]
+		if !rule.enabled {
+			continue
+		}
+		style := inheritColors(rule.style, defaultStyle)
+		match, err := rule.pattern.FindStringMatch(text)
+		for err == nil && match != nil {
+			if match.Length > 0 {
+				highlights = append(highlights, highlight{start: match.Index, length: match.Length, style: style})
+			}
+			match, err = rule.pattern.FindNextMatch(match)
+		}
+	}
+	return highlights
+}
+
+// inheritColors replaces colors that are not set in the style with the colors of the base style
+func inheritColors(style tcell.Style, base tcell.Style) tcell.Style {
+	fg, bg, _ := style.Decompose()
+	baseFg, baseBg, _ := base.Decompose()
+	if fg == tcell.ColorDefault {
+		style = style.Foreground(baseFg)
+	}
+	if bg == tcell.ColorDefault {
+		style = style.Background(baseBg)
+	}
+	return style
+}
@@ -0,0 +1,370 @@
+package logview
+
+import (
+	"sort"
+	"time"
+)
+
+// indexChunkSize is the number of events stored in a single chunk of the event index
+const indexChunkSize = 1024
+
+// eventIndex keeps the first lines of all the events in the order they were appended. Events are stored in chunks
+// of the same size, so the event can be found by its position in constant time and the oldest events can be removed
+// without moving the rest. Events are also indexed by EventID and by timestamp.
+//
+// Each event gets a sequence number when it is added. Events are only removed from the start, so sequence numbers
+// of the events in the index are contiguous and the position of the event is its sequence number minus the sequence
+// number of the first event
+type eventIndex struct {
+	chunks   [][]*logEventLine
+	skipped  int // number of removed events at the start of the first chunk
+	count    int
+	firstSeq uint64
+
+	// sequence numbers of the events with the same EventID, events without EventID are not indexed
+	ids        map[string]*seqList
+	timestamps timestampIndex
+}
+
+// timestampIndex keeps events sorted by timestamp and then by sequence number, so the events that arrived out of order
+// can still be found with binary search. Entries are stored in sorted blocks, so inserting an event that arrived out
+// of order or removing the oldest event only moves the entries of a single block
+type timestampIndex struct {
+	blocks [][]timestampEntry
+	count  int
+}
+
+type timestampEntry struct {
+	timestamp time.Time
+	seq       uint64
+}
+
+// newEventIndex creates an empty index, the first event added to it gets a given sequence number
+func newEventIndex(firstSeq uint64) *eventIndex {
+	return &eventIndex{
+		firstSeq: firstSeq,
+		ids:      make(map[string]*seqList),
+	}
+}
+
+// ScrollToEventIndex scrolls the log view to the event with a given position among the events in memory, the first
+// event has index 0. If the event is hidden by the filter, the nearest visible event is selected. Returns false if
+// there is no such event.
+//
+// Event is found in constant time. Index counts events, not displayed lines: wrapped lines and lines of multiline
+// messages are not counted, there is no lookup by line number. Moving by a number of displayed lines, i.e. with page
+// up and page down keys, walks the lines and takes time proportional to the distance
+func (lv *LogView) ScrollToEventIndex(index int) bool {
+	defer lv.fireOnCurrentChange(lv.current)
+	lv.Lock()
+	defer lv.Unlock()
+
+	return lv.scrollToEventIndex(index)
+}
+
+// ScrollToPercent scrolls the log view to the event at a given percentage of all the events in memory, from 0 to 100.
+// Percentage is calculated by event count, not by displayed lines, see ScrollToEventIndex
+func (lv *LogView) ScrollToPercent(percent float64) bool {
+	defer lv.fireOnCurrentChange(lv.current)
+	lv.Lock()
+	defer lv.Unlock()
+
+	if lv.index.count == 0 {
+		return false
+	}
+	if percent < 0 {
+		percent = 0
+	} else if percent > 100 {
+		percent = 100
+	}
+	return lv.scrollToEventIndex(int(percent * float64(lv.index.count-1) / 100))
+}
+
+// GetCurrentEventIndex returns the position of the current event among the events in memory or -1 if there is no
+// current event, see ScrollToEventIndex
+func (lv *LogView) GetCurrentEventIndex() int {
+	lv.RLock()
+	defer lv.RUnlock()
+
+	if lv.current == nil {
+		return -1
+	}
+	return lv.index.position(lv.current)
+}
+
+// *******************************
+// internal implementation details
+
+func (lv *LogView) scrollToEventIndex(index int) bool {
+	event := lv.index.at(index)
+	if event == nil {
+		return false
+	}
+	event = lv.nearestVisible(event)
+	if event == nil {
+		return false
+	}
+	lv.moveTo(event)
+	return true
+}
+
+// at returns the first line of the event at a given position or nil if the position is out of range
+func (idx *eventIndex) at(position int) *logEventLine {
+	if position < 0 || position >= idx.count {
+		return nil
+	}
+	position += idx.skipped
+	return idx.chunks[position/indexChunkSize][position%indexChunkSize]
+}
+
+// bySeq returns the first line of the event with a given sequence number or nil if it is not in the index
+func (idx *eventIndex) bySeq(seq uint64) *logEventLine {
+	if seq < idx.firstSeq {
+		return nil
+	}
+	return idx.at(int(seq - idx.firstSeq))
+}
+
+// position returns the position of the event the line belongs to
+func (idx *eventIndex) position(event *logEventLine) int {
+	return int(event.seq - idx.firstSeq)
+}
+
+// byID returns the first line of the oldest event with a given EventID
+func (idx *eventIndex) byID(eventID string) *logEventLine {
+	if seqs := idx.ids[eventID]; seqs != nil {
+		return idx.bySeq(seqs.first())
+	}
+	return nil
+}
+
+// add appends the event to the index and assigns its sequence number
+func (idx *eventIndex) add(event *logEventLine) {
+	event.seq = idx.firstSeq + uint64(idx.count)
+	last := len(idx.chunks) - 1
+	if last < 0 || len(idx.chunks[last]) == indexChunkSize {
+		idx.chunks = append(idx.chunks, make([]*logEventLine, 0, indexChunkSize))
+		last++
+	}
+	idx.chunks[last] = append(idx.chunks[last], event)
+	idx.count++
+
+	if seqs := idx.idSeqs(event); seqs != nil {
+		seqs.pushBack(event.seq)
+	}
+	idx.timestamps.insert(timestampEntry{timestamp: event.Timestamp, seq: event.seq})
+}
+
+// removeFirst removes the oldest event from the index. Other events cannot be removed
+func (idx *eventIndex) removeFirst(event *logEventLine) {
+	if idx.count == 0 || event.seq != idx.firstSeq {
+		return
+	}
+	idx.chunks[0][idx.skipped] = nil
+	idx.skipped++
+	idx.count--
+	idx.firstSeq++
+	if idx.skipped == indexChunkSize {
+		idx.chunks = idx.chunks[1:]
+		idx.skipped = 0
+	}
+
+	if seqs := idx.ids[event.EventID]; seqs != nil {
+		seqs.popFront()
+		idx.forgetEmptyID(event.EventID, seqs)
+	}
+	idx.timestamps.remove(timestampEntry{timestamp: event.Timestamp, seq: event.seq})
+}
+
+// prepend adds the event before the first event of the index, the event gets the sequence number preceding the first
+// one. Used to page the events back in from the spill file
+func (idx *eventIndex) prepend(event *logEventLine) {
+	if idx.skipped == 0 {
+		idx.chunks = append([][]*logEventLine{make([]*logEventLine, indexChunkSize)}, idx.chunks...)
+		idx.skipped = indexChunkSize
+	}
+	idx.skipped--
+	idx.firstSeq--
+	idx.count++
+	event.seq = idx.firstSeq
+	idx.chunks[0][idx.skipped] = event
+
+	if seqs := idx.idSeqs(event); seqs != nil {
+		seqs.pushFront(event.seq)
+	}
+	idx.timestamps.insert(timestampEntry{timestamp: event.Timestamp, seq: event.seq})
+}
+
+// remove removes the first or the last event from the index. Other events cannot be removed
+func (idx *eventIndex) remove(event *logEventLine) {
+	if idx.count == 0 {
+		return
+	}
+	if event.seq == idx.firstSeq {
+		idx.removeFirst(event)
+	} else if event.seq == idx.firstSeq+uint64(idx.count)-1 {
+		idx.removeLast(event)
+	}
+}
+
+// removeLast removes the newest event from the index
+func (idx *eventIndex) removeLast(event *logEventLine) {
+	position := idx.skipped + idx.count - 1
+	last := position / indexChunkSize
+	idx.chunks[last] = idx.chunks[last][:position%indexChunkSize]
+	if len(idx.chunks[last]) == 0 {
+		idx.chunks = idx.chunks[:last]
+	}
+	idx.count--
+
+	if seqs := idx.ids[event.EventID]; seqs != nil {
+		seqs.popBack()
+		idx.forgetEmptyID(event.EventID, seqs)
+	}
+	idx.timestamps.remove(timestampEntry{timestamp: event.Timestamp, seq: event.seq})
+}
+
+// replace updates the first line of the event after it was replaced by the wrapped lines
+func (idx *eventIndex) replace(old *logEventLine, new *logEventLine) {
+	position := int(old.seq - idx.firstSeq)
+	if idx.at(position) == old {
+		position += idx.skipped
+		idx.chunks[position/indexChunkSize][position%indexChunkSize] = new
+	}
+}
+
+// idSeqs returns the list of sequence numbers of the events with the same EventID as the event, creating it if
+// needed. Returns nil if the event has no EventID
+func (idx *eventIndex) idSeqs(event *logEventLine) *seqList {
+	if event.EventID == "" {
+		return nil
+	}
+	seqs := idx.ids[event.EventID]
+	if seqs == nil {
+		seqs = &seqList{}
+		idx.ids[event.EventID] = seqs
+	}
+	return seqs
+}
+
+func (idx *eventIndex) forgetEmptyID(eventID string, seqs *seqList) {
+	if seqs.len() == 0 {
+		delete(idx.ids, eventID)
+	}
+}
+
+// firstAtOrAfter returns the first line of the event with the earliest timestamp not before a given one that is
+// accepted by the predicate
+func (idx *eventIndex) firstAtOrAfter(timestamp time.Time, accept func(event *logEventLine) bool) *logEventLine {
+	b, pos := idx.timestamps.search(timestampEntry{timestamp: timestamp})
+	for ; b < len(idx.timestamps.blocks); b, pos = b+1, 0 {
+		for _, entry := range idx.timestamps.blocks[b][pos:] {
+			if event := idx.bySeq(entry.seq); event != nil && accept(event) {
+				return event
+			}
+		}
+	}
+	return nil
+}
+
+func (e timestampEntry) less(other timestampEntry) bool {
+	return e.timestamp.Before(other.timestamp) || e.timestamp.Equal(other.timestamp) && e.seq < other.seq
+}
+
+// search returns the block and the position in the block of the first entry that is not less than a given one
+func (ti *timestampIndex) search(entry timestampEntry) (int, int) {
+	b := sort.Search(len(ti.blocks), func(i int) bool {
+		block := ti.blocks[i]
+		return !block[len(block)-1].less(entry)
+	})
+	if b == len(ti.blocks) {
+		return b, 0
+	}
+	block := ti.blocks[b]
+	return b, sort.Search(len(block), func(i int) bool {
+		return !block[i].less(entry)
+	})
+}
+
+func (ti *timestampIndex) insert(entry timestampEntry) {
+	ti.count++
+	last := len(ti.blocks) - 1
+	if last < 0 || !entry.less(ti.blocks[last][len(ti.blocks[last])-1]) {
+		// events usually arrive in order and are added to the end
+		if last < 0 || len(ti.blocks[last]) >= indexChunkSize {
+			ti.blocks = append(ti.blocks, make([]timestampEntry, 0, indexChunkSize))
+			last++
+		}
+		ti.blocks[last] = append(ti.blocks[last], entry)
+		return
+	}
+	b, pos := ti.search(entry)
+	block := append(ti.blocks[b], timestampEntry{})
+	copy(block[pos+1:], block[pos:])
+	block[pos] = entry
+	if len(block) > 2*indexChunkSize {
+		half := len(block) / 2
+		second := append(make([]timestampEntry, 0, indexChunkSize*2), block[half:]...)
+		ti.blocks = append(ti.blocks, nil)
+		copy(ti.blocks[b+2:], ti.blocks[b+1:])
+		ti.blocks[b+1] = second
+		block = block[:half]
+	}
+	ti.blocks[b] = block
+}
+
+func (ti *timestampIndex) remove(entry timestampEntry) {
+	b, pos := ti.search(entry)
+	if b == len(ti.blocks) || ti.blocks[b][pos].seq != entry.seq {
+		return
+	}
+	block := ti.blocks[b]
+	ti.blocks[b] = append(block[:pos], block[pos+1:]...)
+	if len(ti.blocks[b]) == 0 {
+		ti.blocks = append(ti.blocks[:b], ti.blocks[b+1:]...)
+	}
+	ti.count--
+}
+
+// seqList is a list of sequence numbers that grows and shrinks at both ends in amortized constant time
+type seqList struct {
+	seqs  []uint64
+	start int // number of unused entries at the start of seqs
+}
+
+func (l *seqList) len() int {
+	return len(l.seqs) - l.start
+}
+
+func (l *seqList) first() uint64 {
+	return l.seqs[l.start]
+}
+
+func (l *seqList) pushBack(seq uint64) {
+	l.seqs = append(l.seqs, seq)
+}
+
+func (l *seqList) pushFront(seq uint64) {
+	if l.start == 0 {
+		// leave as much room at the start as there are entries
+		room := maxInt(l.len(), 1)
+		seqs := make([]uint64, room+l.len())
+		copy(seqs[room:], l.seqs)
+		l.seqs, l.start = seqs, room
+	}
+	l.start--
+	l.seqs[l.start] = seq
+}
+
+func (l *seqList) popFront() {
+	l.start++
+	if l.start > l.len() {
+		// compact the list when more than half of it is unused
+		l.seqs = append(l.seqs[:0], l.seqs[l.start:]...)
+		l.start = 0
+	}
+}
+
+func (l *seqList) popBack() {
+	l.seqs = l.seqs[:len(l.seqs)-1]
+}
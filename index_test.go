@@ -0,0 +1,174 @@
+package logview
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogView_Index(t *testing.T) {
+	lv := NewLogView()
+	lv.SetEventLimit(2500)
+	ts := time.Now()
+	lv.AppendEvents(randomEvents(3000, ts))
+
+	if lv.index.count != 2500 || lv.index.timestamps.count != 2500 || len(lv.index.ids) != 2500 {
+		t.Fatalf("Invalid index size: %d", lv.index.count)
+	}
+	if lv.findByEventId("e499") != nil {
+		t.Errorf("Evicted event must not be found")
+	}
+	if e := lv.findByEventId("e2000"); e == nil || e.EventID != "e2000" {
+		t.Errorf("Event not found by id")
+	}
+
+	if !lv.ScrollToEventIndex(0) || lv.GetCurrentEvent().EventID != "e500" || lv.GetCurrentEventIndex() != 0 {
+		t.Errorf("Invalid event at index 0")
+	}
+	if !lv.ScrollToPercent(50) || lv.GetCurrentEvent().EventID != "e1749" || lv.GetCurrentEventIndex() != 1249 {
+		t.Errorf("Invalid event at 50%%: %s", lv.GetCurrentEvent().EventID)
+	}
+	if !lv.ScrollToPercent(100) || lv.GetCurrentEvent().EventID != "e2999" {
+		t.Errorf("Invalid event at 100%%")
+	}
+	if lv.ScrollToEventIndex(2500) {
+		t.Errorf("Index out of range must not be found")
+	}
+
+	if !lv.ScrollToTimestamp(ts.Add(2700*time.Second)) || lv.GetCurrentEvent().EventID != "e2700" {
+		t.Errorf("Invalid event found by timestamp")
+	}
+	if lv.ScrollToTimestamp(ts.Add(3000 * time.Second)) {
+		t.Errorf("Event after the last timestamp must not be found")
+	}
+}
+
+func TestLogView_IndexOutOfOrderTimestamps(t *testing.T) {
+	lv := NewLogView()
+	ts := time.Now()
+	for i, offset := range []int{10, 30, 20, 5, 20} {
+		event := NewLogEvent(strconv.Itoa(i), "Event")
+		event.Timestamp = ts.Add(time.Duration(offset) * time.Second)
+		lv.AppendEvent(event)
+	}
+
+	if !lv.ScrollToTimestamp(ts.Add(15*time.Second)) || lv.GetCurrentEvent().EventID != "2" {
+		t.Errorf("Expected the earliest event after the timestamp, got %s", lv.GetCurrentEvent().EventID)
+	}
+
+	lv.SetFilter(func(event *LogEvent) bool { return event.EventID != "2" })
+	if !lv.ScrollToTimestamp(ts.Add(15*time.Second)) || lv.GetCurrentEvent().EventID != "4" {
+		t.Errorf("Expected the next visible event with the same timestamp, got %s", lv.GetCurrentEvent().EventID)
+	}
+
+	lv.SetEventLimit(3)
+	if lv.index.timestamps.count != 3 || !lv.ScrollToTimestamp(ts) || lv.GetCurrentEvent().EventID != "3" {
+		t.Errorf("Evicted events must be removed from the timestamp index")
+	}
+}
+
+func TestLogView_IndexWrappedEvents(t *testing.T) {
+	lv := NewLogView()
+	lv.pageWidth = 10
+	lv.AppendEvent(NewLogEvent("1", strings.Repeat("a", 25)))
+	lv.AppendEvent(NewLogEvent("1", "short"))
+	lv.AppendEvent(NewLogEvent("2", strings.Repeat("b", 25)))
+
+	for i := 0; i < 3; i++ {
+		e := lv.index.at(i)
+		if e.order > 1 || !lv.isLinked(e) {
+			t.Errorf("Index must point to the first line of the event %d", i)
+		}
+	}
+	if e := lv.findByEventId("1"); e != lv.firstEvent {
+		t.Errorf("The oldest event with the id must be found")
+	}
+	found := lv.FindMatchingEvent("1", func(event *LogEvent) bool {
+		return strings.HasPrefix(event.Message, "b")
+	})
+	if found == nil || found.EventID != "2" {
+		t.Errorf("Matching event not found")
+	}
+}
+
+func TestTimestampIndex(t *testing.T) {
+	var ti timestampIndex
+	ts := time.Now()
+	count := indexChunkSize * 5
+	for i := 0; i < count; i++ {
+		// every other event arrives out of order
+		offset := i
+		if i%2 == 1 {
+			offset = count - i
+		}
+		ti.insert(timestampEntry{timestamp: ts.Add(time.Duration(offset) * time.Second), seq: uint64(i)})
+	}
+	for i := 0; i < count; i += 3 {
+		offset := i
+		if i%2 == 1 {
+			offset = count - i
+		}
+		ti.remove(timestampEntry{timestamp: ts.Add(time.Duration(offset) * time.Second), seq: uint64(i)})
+	}
+
+	var previous *timestampEntry
+	n := 0
+	for _, block := range ti.blocks {
+		if len(block) == 0 || len(block) > 2*indexChunkSize {
+			t.Fatalf("Invalid block size %d", len(block))
+		}
+		for i := range block {
+			if previous != nil && block[i].less(*previous) {
+				t.Fatalf("Entries are not sorted")
+			}
+			previous = &block[i]
+			n++
+		}
+	}
+	if n != ti.count || n != count-count/3-1 {
+		t.Errorf("Invalid number of entries: %d, count %d", n, ti.count)
+	}
+}
+
+func TestEventIndex_IDs(t *testing.T) {
+	idx := newEventIndex(10)
+	for _, id := range []string{"a", "", "b", "a", ""} {
+		idx.add(&logEventLine{EventID: id})
+	}
+	if len(idx.ids) != 2 || idx.ids["a"].len() != 2 || idx.byID("") != nil {
+		t.Errorf("Events without EventID must not be indexed: %v", idx.ids)
+	}
+	idx.prepend(&logEventLine{EventID: "a"})
+	if e := idx.byID("a"); e == nil || e.seq != 9 {
+		t.Errorf("Prepended event must be the oldest one with the id")
+	}
+	idx.removeFirst(idx.at(0))
+	idx.removeFirst(idx.at(0))
+	if e := idx.byID("a"); e == nil || e.seq != 13 {
+		t.Errorf("Removed events must not be found by id")
+	}
+	idx.removeLast(idx.at(idx.count - 1))
+	idx.removeLast(idx.at(idx.count - 1))
+	if idx.byID("a") != nil || len(idx.ids) != 1 {
+		t.Errorf("Ids of removed events must be forgotten: %v", idx.ids)
+	}
+}
+
+func TestSeqList(t *testing.T) {
+	var l seqList
+	for i := uint64(0); i < 100; i++ {
+		l.pushFront(1000 - i)
+		l.pushBack(1001 + i)
+	}
+	for i := 0; i < 150; i++ {
+		l.popFront()
+	}
+	if l.len() != 50 || l.first() != 1051 {
+		t.Errorf("Invalid list: %d entries, first %d", l.len(), l.first())
+	}
+	l.popBack()
+	if l.len() != 49 || l.seqs[len(l.seqs)-1] != 1099 {
+		t.Errorf("Invalid list after removing the last entry: %d entries", l.len())
+	}
+}
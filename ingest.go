@@ -0,0 +1,468 @@
+package logview
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EventSink receives batches of events from Ingester. Both LogView and LogVelocityView are event sinks
+type EventSink interface {
+	AppendEvents(events []*LogEvent)
+}
+
+// ErrIngesterStarted is returned when trying to start ingester that is already running
+var ErrIngesterStarted = errors.New("ingester is already started")
+
+// Ingester reads lines from a file or any io.Reader, converts them into events with a Parser and appends them
+// to the event sinks in batches.
+//
+// Batch is at most batch size events long, it is delivered to sinks as soon as it is full or when the flush interval
+// passes. When sinks cannot keep up, events are accumulated in a bounded buffer and when it is full reading stops
+// until there is room in the buffer again.
+//
+// Trailing CR and LF characters are removed from the lines and empty lines are skipped. If the parser returns nil
+// event for a line, the line is skipped after the error is reported.
+//
+// When tailing a file, ingester detects rotation (the file at the path is replaced by another one) and truncation,
+// reopening or rewinding the file as needed. If the file doesn't exist yet, ingester waits until it is created.
+type Ingester struct {
+	path   string
+	reader io.Reader
+	parser Parser
+	sinks  []EventSink
+
+	batchSize     int
+	bufferSize    int
+	flushInterval time.Duration
+	pollInterval  time.Duration
+	startAtEnd    bool
+
+	onError func(line string, err error)
+	onBatch func(events []*LogEvent)
+
+	cancel context.CancelFunc
+	done   chan struct{}
+	err    error
+
+	sync.Mutex
+}
+
+// plainParser uses the whole line as an event message
+type plainParser struct {
+	parserBase
+}
+
+func (p *plainParser) Parse(line string) (*LogEvent, error) {
+	return p.newEvent(line), nil
+}
+
+// NewReaderIngester creates an ingester that reads lines from the reader until EOF.
+// If parser is nil, each line becomes an event message as is.
+func NewReaderIngester(reader io.Reader, parser Parser) *Ingester {
+	ingester := newIngester(parser)
+	ingester.reader = reader
+	return ingester
+}
+
+// NewFileIngester creates an ingester that tails a file, similar to tail -F.
+// If parser is nil, each line becomes an event message as is.
+func NewFileIngester(path string, parser Parser) *Ingester {
+	ingester := newIngester(parser)
+	ingester.path = path
+	return ingester
+}
+
+func newIngester(parser Parser) *Ingester {
+	if parser == nil {
+		parser = &plainParser{}
+	}
+	return &Ingester{
+		parser:        parser,
+		batchSize:     1000,
+		bufferSize:    10000,
+		flushInterval: 100 * time.Millisecond,
+		pollInterval:  250 * time.Millisecond,
+	}
+}
+
+// AddSink adds an event sink that will receive parsed events. Sinks must be added before the ingester is started
+func (in *Ingester) AddSink(sink EventSink) {
+	in.Lock()
+	defer in.Unlock()
+
+	in.sinks = append(in.sinks, sink)
+}
+
+// SetBatchSize sets the maximum number of events delivered to sinks at once. Default is 1000
+func (in *Ingester) SetBatchSize(size int) {
+	in.Lock()
+	defer in.Unlock()
+
+	in.batchSize = size
+}
+
+// SetBufferSize sets the maximum number of parsed events waiting to be delivered to sinks. When buffer is full
+// reading is paused. Default is 10000
+func (in *Ingester) SetBufferSize(size int) {
+	in.Lock()
+	defer in.Unlock()
+
+	in.bufferSize = size
+}
+
+// SetFlushInterval sets how often incomplete batches of events are delivered to sinks. Default is 100ms
+func (in *Ingester) SetFlushInterval(interval time.Duration) {
+	in.Lock()
+	defer in.Unlock()
+
+	in.flushInterval = interval
+}
+
+// SetPollInterval sets how often the tailed file is checked for new data, rotation and truncation. Default is 250ms
+func (in *Ingester) SetPollInterval(interval time.Duration) {
+	in.Lock()
+	defer in.Unlock()
+
+	in.pollInterval = interval
+}
+
+// SetStartAtEnd enables skipping of existing file contents, only lines appended after the start are ingested.
+// This setting is ignored when reading from io.Reader
+func (in *Ingester) SetStartAtEnd(enabled bool) {
+	in.Lock()
+	defer in.Unlock()
+
+	in.startAtEnd = enabled
+}
+
+// SetOnError sets a listener that is called for every line that cannot be parsed. The event for such line is still
+// delivered to sinks
+func (in *Ingester) SetOnError(listener func(line string, err error)) {
+	in.Lock()
+	defer in.Unlock()
+
+	in.onError = listener
+}
+
+// SetOnBatch sets a listener that is called after every batch is delivered to sinks. It can be used to redraw the
+// application, i.e. with tview.Application.QueueUpdateDraw
+func (in *Ingester) SetOnBatch(listener func(events []*LogEvent)) {
+	in.Lock()
+	defer in.Unlock()
+
+	in.onBatch = listener
+}
+
+// Start starts reading in background. Reading stops when context is cancelled, Stop is called or, for io.Reader,
+// EOF is reached
+func (in *Ingester) Start(ctx context.Context) error {
+	in.Lock()
+	defer in.Unlock()
+
+	if in.done != nil {
+		return ErrIngesterStarted
+	}
+	ctx, in.cancel = context.WithCancel(ctx)
+	in.done = make(chan struct{})
+	in.err = nil
+
+	events := make(chan *LogEvent, in.bufferSize)
+	go func() {
+		var err error
+		if in.reader != nil {
+			err = in.readStream(ctx, events)
+		} else {
+			err = in.tailFile(ctx, events)
+		}
+		in.Lock()
+		in.err = err
+		in.Unlock()
+		close(events)
+	}()
+	go in.deliver(ctx, events, in.done)
+	return nil
+}
+
+// Stop stops reading and waits until ingester finishes delivering the events that were already read.
+//
+// Reading from io.Reader cannot be interrupted, if reader is blocked the reading goroutine will exit after the read
+// returns.
+func (in *Ingester) Stop() {
+	in.Lock()
+	cancel, done := in.cancel, in.done
+	in.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+}
+
+// Wait blocks until ingester stops and returns the error that stopped it, if any
+func (in *Ingester) Wait() error {
+	in.Lock()
+	done := in.done
+	in.Unlock()
+
+	if done != nil {
+		<-done
+	}
+
+	in.Lock()
+	defer in.Unlock()
+	return in.err
+}
+
+// *******************************
+// internal implementation details
+
+// deliver collects events into batches and delivers them to sinks. When the context is cancelled, events waiting in
+// the buffer are delivered before ingester stops
+func (in *Ingester) deliver(ctx context.Context, events <-chan *LogEvent, done chan struct{}) {
+	defer func() {
+		// ingester can be started again as soon as it stops
+		in.Lock()
+		if in.done == done {
+			in.cancel()
+			in.cancel = nil
+			in.done = nil
+		}
+		in.Unlock()
+		close(done)
+	}()
+
+	in.Lock()
+	batchSize, interval := in.batchSize, in.flushInterval
+	in.Unlock()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	batch := make([]*LogEvent, 0, batchSize)
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				in.flush(batch)
+				return
+			}
+			batch = append(batch, event)
+			if len(batch) >= batchSize {
+				batch = in.flush(batch)
+			}
+		case <-ticker.C:
+			batch = in.flush(batch)
+		case <-ctx.Done():
+			in.flush(in.drainBuffer(batch, events, batchSize))
+			return
+		}
+	}
+}
+
+// drainBuffer delivers the events waiting in the buffer without blocking, returns the last incomplete batch
+func (in *Ingester) drainBuffer(batch []*LogEvent, events <-chan *LogEvent, batchSize int) []*LogEvent {
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return batch
+			}
+			batch = append(batch, event)
+			if len(batch) >= batchSize {
+				batch = in.flush(batch)
+			}
+		default:
+			return batch
+		}
+	}
+}
+
+func (in *Ingester) flush(batch []*LogEvent) []*LogEvent {
+	if len(batch) == 0 {
+		return batch
+	}
+	in.Lock()
+	sinks, onBatch := in.sinks, in.onBatch
+	in.Unlock()
+
+	for _, sink := range sinks {
+		sink.AppendEvents(batch)
+	}
+	if onBatch != nil {
+		onBatch(batch)
+	}
+	return make([]*LogEvent, 0, cap(batch))
+}
+
+// emit parses the line and sends it for delivery. Returns false if context was cancelled
+func (in *Ingester) emit(ctx context.Context, events chan<- *LogEvent, line string) bool {
+	line = strings.TrimRight(line, "\r\n")
+	if line == "" {
+		return true
+	}
+	if ctx.Err() != nil {
+		return false
+	}
+	event, err := in.parser.Parse(line)
+	if err != nil {
+		in.Lock()
+		onError := in.onError
+		in.Unlock()
+		if onError != nil {
+			onError(line, err)
+		}
+	}
+	if event == nil { // custom parser didn't keep the line
+		return true
+	}
+	select {
+	case events <- event:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (in *Ingester) readStream(ctx context.Context, events chan<- *LogEvent) error {
+	reader := bufio.NewReader(in.reader)
+	for {
+		line, err := reader.ReadString('\n')
+		if len(line) > 0 && !in.emit(ctx, events, line) {
+			return nil
+		}
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+	}
+}
+
+func (in *Ingester) tailFile(ctx context.Context, events chan<- *LogEvent) error {
+	in.Lock()
+	startAtEnd, pollInterval := in.startAtEnd, in.pollInterval
+	in.Unlock()
+
+	file, created, err := in.waitForFile(ctx, pollInterval)
+	if file == nil {
+		return err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	var offset int64
+	if startAtEnd && !created { // file created after the start contains only new lines
+		if offset, err = file.Seek(0, io.SeekEnd); err != nil {
+			return err
+		}
+	}
+
+	reader := bufio.NewReader(file)
+	partial := ""
+	drained := false // the rotated file was read to the end, but the new one is not opened yet
+	timer := time.NewTimer(pollInterval)
+	defer timer.Stop()
+	for {
+		line, err := reader.ReadString('\n')
+		offset += int64(len(line))
+		if err == nil {
+			if !in.emit(ctx, events, partial+line) {
+				return nil
+			}
+			partial = ""
+			continue
+		} else if err != io.EOF {
+			return err
+		}
+		// keep incomplete line until the rest of it is written
+		partial += line
+
+		timer.Reset(pollInterval)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-timer.C:
+		}
+
+		pathInfo, err := os.Stat(in.path)
+		if err != nil { // file might be missing while it is being rotated
+			continue
+		}
+		fileInfo, err := file.Stat()
+		if err != nil {
+			return err
+		}
+		if !os.SameFile(pathInfo, fileInfo) {
+			// file was rotated, read what's left in the old file and switch to the new one
+			if !drained {
+				if !in.drain(ctx, events, reader, partial) {
+					return nil
+				}
+				drained = true
+				partial = ""
+			}
+			newFile, err := os.Open(in.path)
+			if err != nil { // the old file is drained already, retry opening on the next poll
+				continue
+			}
+			_ = file.Close()
+			file = newFile
+			reader.Reset(file)
+			offset = 0
+			drained = false
+		} else if pathInfo.Size() < offset {
+			// file was truncated, start from the beginning
+			if _, err = file.Seek(0, io.SeekStart); err != nil {
+				return err
+			}
+			reader.Reset(file)
+			offset = 0
+			partial = ""
+		}
+	}
+}
+
+// waitForFile opens the tailed file, polling until it is created. Returns true if the file didn't exist at the start
+// and nil file if the context is cancelled or the file cannot be opened
+func (in *Ingester) waitForFile(ctx context.Context, pollInterval time.Duration) (*os.File, bool, error) {
+	created := false
+	for {
+		file, err := os.Open(in.path)
+		if err == nil {
+			return file, created, nil
+		} else if !os.IsNotExist(err) {
+			return nil, false, err
+		}
+		created = true
+		select {
+		case <-ctx.Done():
+			return nil, false, nil
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// drain emits all the remaining lines from the reader, including the final incomplete line
+func (in *Ingester) drain(ctx context.Context, events chan<- *LogEvent, reader *bufio.Reader, partial string) bool {
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return in.emit(ctx, events, partial+line)
+		}
+		if !in.emit(ctx, events, partial+line) {
+			return false
+		}
+		partial = ""
+	}
+}
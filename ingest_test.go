@@ -0,0 +1,281 @@
+package logview
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type collectingSink struct {
+	events  []*LogEvent
+	batches int
+	sync.Mutex
+}
+
+func (s *collectingSink) AppendEvents(events []*LogEvent) {
+	s.Lock()
+	defer s.Unlock()
+	s.events = append(s.events, events...)
+	s.batches++
+}
+
+func (s *collectingSink) messages() []string {
+	s.Lock()
+	defer s.Unlock()
+	result := make([]string, len(s.events))
+	for i, e := range s.events {
+		result[i] = e.Message
+	}
+	return result
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for condition")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestIngester_Reader(t *testing.T) {
+	lv := NewLogView()
+	velocity := NewLogVelocityView(time.Second)
+	sink := &collectingSink{}
+	parser := NewLogfmtParser()
+	ingester := NewReaderIngester(strings.NewReader("level=error msg=one\nmsg=two\r\n\nmsg=\"broken\nmsg=three"), parser)
+	ingester.AddSink(lv)
+	ingester.AddSink(velocity)
+	ingester.AddSink(sink)
+	ingester.SetBatchSize(2)
+	ingester.SetFlushInterval(time.Millisecond)
+	var errorLines []string
+	ingester.SetOnError(func(line string, err error) {
+		errorLines = append(errorLines, line)
+	})
+
+	if err := ingester.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start: %v", err)
+	}
+	if err := ingester.Wait(); err != nil {
+		t.Fatalf("Ingester failed: %v", err)
+	}
+
+	if strings.Join(sink.messages(), ",") != `one,two,msg="broken,three` {
+		t.Errorf("Invalid events ingested: %v", sink.messages())
+	}
+	if sink.batches < 2 {
+		t.Errorf("Events should be delivered in batches of 2, got %d batches", sink.batches)
+	}
+	if len(errorLines) != 1 || errorLines[0] != `msg="broken` {
+		t.Errorf("Parse error must be reported for broken line, got %v", errorLines)
+	}
+	if lv.GetEventCount() != 4 || lv.GetFirstEvent().Level != LogLevelError {
+		t.Errorf("Events must be appended to log view, got %d", lv.GetEventCount())
+	}
+}
+
+// skippingParser returns nil event for the lines starting with "skip"
+type skippingParser struct {
+	plainParser
+}
+
+func (p *skippingParser) Parse(line string) (*LogEvent, error) {
+	if strings.HasPrefix(line, "skip") {
+		return nil, ErrUnrecognizedLine
+	}
+	return p.plainParser.Parse(line)
+}
+
+func TestIngester_NilEvents(t *testing.T) {
+	sink := &collectingSink{}
+	ingester := NewReaderIngester(strings.NewReader("one\nskip me\ntwo\n"), &skippingParser{})
+	ingester.AddSink(sink)
+	errorCount := 0
+	ingester.SetOnError(func(line string, err error) {
+		errorCount++
+	})
+	_ = ingester.Start(context.Background())
+	if err := ingester.Wait(); err != nil {
+		t.Fatalf("Ingester failed: %v", err)
+	}
+	if strings.Join(sink.messages(), ",") != "one,two" || errorCount != 1 {
+		t.Errorf("Nil events must be skipped after the error is reported, got %v, %d errors", sink.messages(), errorCount)
+	}
+}
+
+// blockingSink blocks delivery of the first batch until released
+type blockingSink struct {
+	collectingSink
+	entered chan struct{}
+	release chan struct{}
+	once    sync.Once
+}
+
+func newBlockingSink() *blockingSink {
+	return &blockingSink{entered: make(chan struct{}), release: make(chan struct{})}
+}
+
+func (s *blockingSink) AppendEvents(events []*LogEvent) {
+	s.once.Do(func() {
+		close(s.entered)
+		<-s.release
+	})
+	s.collectingSink.AppendEvents(events)
+}
+
+// countingParser counts parsed lines
+type countingParser struct {
+	plainParser
+	count int32
+}
+
+func (p *countingParser) Parse(line string) (*LogEvent, error) {
+	atomic.AddInt32(&p.count, 1)
+	return p.plainParser.Parse(line)
+}
+
+func (p *countingParser) parsed() int {
+	return int(atomic.LoadInt32(&p.count))
+}
+
+func TestIngester_Backpressure(t *testing.T) {
+	sink := newBlockingSink()
+	parser := &countingParser{}
+	ingester := NewReaderIngester(strings.NewReader(strings.Repeat("line\n", 100)), parser)
+	ingester.AddSink(sink)
+	ingester.SetBatchSize(10)
+	ingester.SetBufferSize(5)
+	ingester.SetFlushInterval(time.Hour)
+
+	_ = ingester.Start(context.Background())
+	// full batch is delivered without waiting for the flush interval
+	<-sink.entered
+	// while the sink is blocked, reading stops when the buffer is full: batch of 10, 5 buffered and 1 waiting
+	waitFor(t, func() bool { return parser.parsed() >= 16 })
+	if parser.parsed() != 16 {
+		t.Errorf("Reading must stop when the buffer is full, %d lines read", parser.parsed())
+	}
+	close(sink.release)
+	if err := ingester.Wait(); err != nil {
+		t.Fatalf("Ingester failed: %v", err)
+	}
+	if len(sink.messages()) != 100 || sink.batches != 10 {
+		t.Errorf("All events must be delivered in full batches, got %d in %d batches", len(sink.messages()), sink.batches)
+	}
+}
+
+func TestIngester_Stop(t *testing.T) {
+	sink := newBlockingSink()
+	parser := &countingParser{}
+	ingester := NewReaderIngester(strings.NewReader(strings.Repeat("line\n", 100)), parser)
+	ingester.AddSink(sink)
+	ingester.SetBatchSize(10)
+	ingester.SetBufferSize(5)
+	ingester.SetFlushInterval(time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	_ = ingester.Start(ctx)
+	<-sink.entered
+	waitFor(t, func() bool { return parser.parsed() >= 16 })
+	cancel()
+	close(sink.release)
+	ingester.Stop()
+	// the line waiting for the room in the buffer may be dropped, buffered events must be delivered
+	if count := len(sink.messages()); count < 15 || count > parser.parsed() {
+		t.Errorf("Buffered events must be delivered on stop, got %d of %d", count, parser.parsed())
+	}
+
+	// ingester can be restarted after it stopped by itself
+	ingester = NewReaderIngester(strings.NewReader("line\n"), nil)
+	_ = ingester.Start(context.Background())
+	_ = ingester.Wait()
+	if err := ingester.Start(context.Background()); err != nil {
+		t.Errorf("Ingester must be restartable after EOF: %v", err)
+	}
+	_ = ingester.Wait()
+}
+
+func TestIngester_TailFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	if err := os.WriteFile(path, []byte("first\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	sink := &collectingSink{}
+	ingester := NewFileIngester(path, nil)
+	ingester.AddSink(sink)
+	ingester.SetFlushInterval(time.Millisecond)
+	ingester.SetPollInterval(5 * time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := ingester.Start(ctx); err != nil {
+		t.Fatalf("Failed to start: %v", err)
+	}
+
+	appendToFile := func(text string) {
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = f.WriteString(text)
+		_ = f.Close()
+	}
+	messages := func() string {
+		return strings.Join(sink.messages(), ",")
+	}
+
+	appendToFile("sec")
+	time.Sleep(20 * time.Millisecond)
+	appendToFile("ond\n")
+	waitFor(t, func() bool { return messages() == "first,second" })
+
+	// rotation
+	appendToFile("last in old file\n")
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("rotated\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { return messages() == "first,second,last in old file,rotated" })
+
+	// truncation
+	if err := os.WriteFile(path, []byte("new\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { return messages() == "first,second,last in old file,rotated,new" })
+
+	cancel()
+	if err := ingester.Wait(); err != nil {
+		t.Errorf("Ingester failed: %v", err)
+	}
+}
+
+func TestIngester_TailMissingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	sink := &collectingSink{}
+	ingester := NewFileIngester(path, nil)
+	ingester.AddSink(sink)
+	ingester.SetFlushInterval(time.Millisecond)
+	ingester.SetPollInterval(5 * time.Millisecond)
+	if err := ingester.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start: %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte("created\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { return strings.Join(sink.messages(), ",") == "created" })
+	ingester.Stop()
+	if err := ingester.Wait(); err != nil {
+		t.Errorf("Ingester failed: %v", err)
+	}
+}
@@ -55,6 +55,23 @@ type Key struct {
 	MoveNextPage      []string
 
 	ShowContextMenu []string
+
+	Search         []string
+	SearchBackward []string
+	SearchNext     []string
+	SearchPrev     []string
+
+	CycleMinLevel []string
+
+	ToggleBookmark []string
+	NextBookmark   []string
+	PrevBookmark   []string
+	SetMark        []string
+	JumpToMark     []string
+
+	SelectUp      []string
+	SelectDown    []string
+	CopySelection []string
 }
 
 // Keys defines the keyboard shortcuts of an application.
@@ -85,6 +102,23 @@ var Keys = Key{
 	MoveNextPage:      []string{"PageDown", "Ctrl+F"},
 
 	ShowContextMenu: []string{"Alt+Enter"},
+
+	Search:         []string{"/"},
+	SearchBackward: []string{"?"},
+	SearchNext:     []string{"n"},
+	SearchPrev:     []string{"N"},
+
+	CycleMinLevel: []string{"L"},
+
+	ToggleBookmark: []string{"b"},
+	NextBookmark:   []string{"]"},
+	PrevBookmark:   []string{"["},
+	SetMark:        []string{"m"},
+	JumpToMark:     []string{"'"},
+
+	SelectUp:      []string{"Shift+Up"},
+	SelectDown:    []string{"Shift+Down"},
+	CopySelection: []string{"y"},
 }
 
 // HitShortcut returns whether the EventKey provided is present in one or more
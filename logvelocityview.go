@@ -13,14 +13,11 @@ type LogVelocityView struct {
 	*gui.Box
 
 	defaultStyle tcell.Style
-	errorColor   tcell.Color
-	warningColor tcell.Color
+	levelColors  map[LogLevel]tcell.Color
 
 	showLogLevel LogLevel
 	bucketWidth  int64
-	infoBuckets  map[int64]int
-	errorBuckets map[int64]int
-	warnBuckets  map[int64]int
+	buckets      map[LogLevel]map[int64]int
 	height       int
 	width        int
 
@@ -48,12 +45,17 @@ func NewLogVelocityView(bucketWidth time.Duration) *LogVelocityView {
 	return &LogVelocityView{
 		Box:          gui.NewBox(),
 		bucketWidth:  int64(bucketWidth.Seconds()),
-		infoBuckets:  make(map[int64]int),
-		warnBuckets:  make(map[int64]int),
-		errorBuckets: make(map[int64]int),
+		buckets:      make(map[LogLevel]map[int64]int),
 		defaultStyle: tcell.StyleDefault.Foreground(gui.Styles.PrimaryTextColor).Background(tcell.Color239),
-		errorColor:   tcell.ColorIndianRed,
-		warningColor: tcell.ColorSaddleBrown,
+		levelColors: map[LogLevel]tcell.Color{
+			LogLevelTrace:    tcell.ColorGray,
+			LogLevelDebug:    tcell.ColorSilver,
+			LogLevelNotice:   tcell.ColorLightSkyBlue,
+			LogLevelWarning:  tcell.ColorSaddleBrown,
+			LogLevelError:    tcell.ColorIndianRed,
+			LogLevelCritical: tcell.ColorFireBrick,
+			LogLevelFatal:    tcell.ColorDarkRed,
+		},
 		showLogLevel: LogLevelAll,
 		anchor:       nil,
 	}
@@ -95,36 +97,22 @@ func (lh *LogVelocityView) AppendLogEvent(event *LogEvent) {
 	lh.Lock()
 	defer lh.Unlock()
 
-	key := event.Timestamp.Unix() / lh.bucketWidth
+	lh.append(event)
+}
 
-	var b map[int64]int
-	switch event.Level {
-	case LogLevelError:
-		b = lh.errorBuckets
-	case LogLevelWarning:
-		b = lh.warnBuckets
-	default:
-		b = lh.infoBuckets
-	}
+// AppendEvents adds multiple events to a velocity chart in a single batch
+func (lh *LogVelocityView) AppendEvents(events []*LogEvent) {
+	lh.Lock()
+	defer lh.Unlock()
 
-	if v, ok := b[key]; ok {
-		b[key] = v + 1
-	} else {
-		b[key] = 1
+	for _, event := range events {
+		lh.append(event)
 	}
 }
 
 // SetShowLogLevel sets the log level of events that should be displayed in the velocity view
 //
-// Supported values are:
-//
-//  - LogLevelInfo - show all events but warning and errors
-//
-//	- LogLevelWarning
-//
-//  - LogLevelError
-//
-//  - LogLevelAll - show all events
+// Any log level can be used to show only events of that level, LogLevelAll shows all events
 func (lh *LogVelocityView) SetShowLogLevel(logLevel LogLevel) {
 	lh.Lock()
 	defer lh.Unlock()
@@ -132,6 +120,15 @@ func (lh *LogVelocityView) SetShowLogLevel(logLevel LogLevel) {
 	lh.showLogLevel = logLevel
 }
 
+// SetLevelColor sets the color of the bars when the velocity view displays events of a given level.
+// tcell.ColorDefault uses the color of the default style
+func (lh *LogVelocityView) SetLevelColor(level LogLevel, color tcell.Color) {
+	lh.Lock()
+	defer lh.Unlock()
+
+	lh.levelColors[level] = color
+}
+
 // GetShowLogLevel returns the log level of events that are be displayed in the velocity view
 func (lh *LogVelocityView) GetShowLogLevel() LogLevel {
 	lh.RLock()
@@ -213,6 +210,22 @@ func (lh *LogVelocityView) Draw(screen tcell.Screen) {
 // ****************
 // Internal methods
 
+func (lh *LogVelocityView) append(event *LogEvent) {
+	key := event.Timestamp.Unix() / lh.bucketWidth
+
+	b, ok := lh.buckets[event.Level]
+	if !ok {
+		b = make(map[int64]int)
+		lh.buckets[event.Level] = b
+	}
+
+	if v, ok := b[key]; ok {
+		b[key] = v + 1
+	} else {
+		b[key] = 1
+	}
+}
+
 func (lh *LogVelocityView) bucketValue(bucket map[int64]int, key int64) int {
 	if v, ok := bucket[key]; ok {
 		return v
@@ -224,19 +237,13 @@ func (lh *LogVelocityView) bucketValue(bucket map[int64]int, key int64) int {
 func (lh *LogVelocityView) values(key int64, count int) []int {
 	results := make([]int, count)
 	for i := count - 1; i >= 0; i-- {
-		var value int
-		switch lh.showLogLevel {
-		case LogLevelWarning:
-			value = lh.bucketValue(lh.warnBuckets, key)
-		case LogLevelError:
-			value = lh.bucketValue(lh.errorBuckets, key)
-		case LogLevelInfo:
-			value = lh.bucketValue(lh.infoBuckets, key)
-		case LogLevelAll:
-			value = lh.bucketValue(lh.infoBuckets, key) + lh.bucketValue(lh.warnBuckets, key) +
-				lh.bucketValue(lh.errorBuckets, key)
-		default:
-			value = 0
+		value := 0
+		if lh.showLogLevel == LogLevelAll {
+			for _, bucket := range lh.buckets {
+				value += lh.bucketValue(bucket, key)
+			}
+		} else {
+			value = lh.bucketValue(lh.buckets[lh.showLogLevel], key)
 		}
 		results[i] = value
 		key = key - 1
@@ -256,11 +263,8 @@ func (lh *LogVelocityView) drawHistogram(screen tcell.Screen, x, y, width, heigh
 		values[i] = int(float64(v) * scale)
 	}
 	style := lh.defaultStyle
-	switch lh.showLogLevel {
-	case LogLevelWarning:
-		style = style.Foreground(lh.warningColor)
-	case LogLevelError:
-		style = style.Foreground(lh.errorColor)
+	if color, ok := lh.levelColors[lh.showLogLevel]; ok && color != tcell.ColorDefault {
+		style = style.Foreground(color)
 	}
 
 	index := len(values) - 1
@@ -357,9 +361,7 @@ func durationToString(d int64) string {
 }
 
 func (lh *LogVelocityView) reset() {
-	lh.infoBuckets = make(map[int64]int)
-	lh.warnBuckets = make(map[int64]int)
-	lh.errorBuckets = make(map[int64]int)
+	lh.buckets = make(map[LogLevel]map[int64]int)
 }
 
 func (lh *LogVelocityView) scaleForDuration(duration time.Duration) {
@@ -49,3 +49,32 @@ func TestLogVelocityView_Scale(t *testing.T) {
 		t.Errorf("Should have 5 minute bucket size, but got %d", velocity.bucketWidth)
 	}
 }
+
+func TestLogVelocityView_LevelBuckets(t *testing.T) {
+	velocity := NewLogVelocityView(time.Second)
+	ts := time.Date(2021, 03, 01, 10, 0, 0, 0, time.Local)
+	velocity.SetAnchor(ts)
+	levels := []LogLevel{LogLevelTrace, LogLevelDebug, LogLevelDebug, LogLevelNotice, LogLevelFatal, LogLevelInfo}
+	events := make([]*LogEvent, len(levels))
+	for i, level := range levels {
+		events[i] = &LogEvent{Timestamp: ts, Level: level}
+	}
+	velocity.AppendEvents(events)
+
+	key := velocity.timeAnchor()
+	expected := map[LogLevel]int{
+		LogLevelAll:     6,
+		LogLevelDebug:   2,
+		LogLevelFatal:   1,
+		LogLevelError:   0,
+		LogLevelInfo:    1,
+		LogLevelWarning: 0,
+	}
+	for level, count := range expected {
+		velocity.SetShowLogLevel(level)
+		values := velocity.values(key, 1)
+		if values[0] != count {
+			t.Errorf("Expected %d events for level %v, got %d", count, level, values[0])
+		}
+	}
+}
@@ -1,16 +1,13 @@
 package logview
 
 import (
-	"fmt"
 	"github.com/dlclark/regexp2"
 	"github.com/gdamore/tcell/v2"
 	gui "github.com/rivo/tview"
 	"regexp"
-	"strconv"
 	"strings"
 	"sync"
 	"time"
-	"unicode/utf8"
 )
 
 type styledSpan struct {
@@ -24,11 +21,16 @@ type logEventLine struct {
 	Source     string
 	Timestamp  time.Time
 	Level      LogLevel
+	Fields     map[string]string
 	Runes      []rune
 	lineID     uint
 	previous   *logEventLine
 	next       *logEventLine
 	styleSpans []styledSpan
+	ansiSpans  []ansiSpan
+	// message as it was appended, before tabs are expanded and escape sequences are processed. Empty if it is the
+	// same as Runes
+	original string
 
 	// start and end determine slice of LogEvent.Message this event line covers
 	// for unwrapped string this will be the whole length of the message starting at position 0
@@ -45,15 +47,35 @@ type logEventLine struct {
 	// event merging, then merged parts will be separated by newlines. We need to know if there are any
 	// so we can decide if we need to wrap them.
 	hasNewLines bool
-}
-
-func (e logEventLine) AsLogEvent() *LogEvent {
+	// line continues the previous line of the event that was wrapped
+	continued bool
+	// event doesn't match the filter and must not be displayed. All the lines of a wrapped event share the same value
+	filteredOut bool
+	// ranges of the message matching the search pattern, shared by all the lines of a wrapped event
+	searchMatches []textRange
+	// generations of highlighting and wrapping settings the line was processed with, see LogView.invalidateWrap.
+	// Zero means the line was not processed yet
+	colorGeneration uint
+	wrapGeneration  uint
+	// sequence number of the event in the event index, shared by all the lines of a wrapped event
+	seq uint64
+	// approximate size of the event and its wrapped lines, kept in the first line, see LogView.updateMemoryUsage
+	memorySize uint64
+	// event is bookmarked. All the lines of a wrapped event share the same value
+	bookmarked bool
+}
+
+func (e *logEventLine) AsLogEvent() *LogEvent {
+	if e == nil {
+		return nil
+	}
 	return &LogEvent{
 		EventID:   e.EventID,
 		Source:    e.Source,
 		Timestamp: e.Timestamp,
 		Level:     e.Level,
-		Message:   string(e.Runes),
+		Message:   e.originalMessage(),
+		Fields:    copyFields(e.Fields),
 	}
 }
 
@@ -61,26 +83,46 @@ func (e logEventLine) message() string {
 	return string(e.Runes)
 }
 
+// originalMessage returns the message as it was appended
+func (e logEventLine) originalMessage() string {
+	if e.original != "" {
+		return e.original
+	}
+	return string(e.Runes)
+}
+
 func (e logEventLine) getLineCount() uint {
 	return e.lineCount
 }
 
 func (e *logEventLine) copy() *logEventLine {
 	eventCopy := &logEventLine{
-		EventID:     e.EventID,
-		Source:      e.Source,
-		Timestamp:   e.Timestamp,
-		Level:       e.Level,
-		Runes:       e.Runes,
-		lineID:      e.lineID,
-		previous:    e.previous,
-		next:        e.next,
-		styleSpans:  e.styleSpans,
-		start:       e.start,
-		end:         e.end,
-		order:       e.order,
-		lineCount:   e.lineCount,
-		hasNewLines: e.hasNewLines,
+		EventID:       e.EventID,
+		Source:        e.Source,
+		Timestamp:     e.Timestamp,
+		Level:         e.Level,
+		Fields:        e.Fields,
+		Runes:         e.Runes,
+		lineID:        e.lineID,
+		previous:      e.previous,
+		next:          e.next,
+		styleSpans:    e.styleSpans,
+		ansiSpans:     e.ansiSpans,
+		original:      e.original,
+		start:         e.start,
+		end:           e.end,
+		order:         e.order,
+		lineCount:     e.lineCount,
+		hasNewLines:   e.hasNewLines,
+		continued:     e.continued,
+		filteredOut:   e.filteredOut,
+		searchMatches: e.searchMatches,
+
+		colorGeneration: e.colorGeneration,
+		wrapGeneration:  e.wrapGeneration,
+		seq:             e.seq,
+		memorySize:      e.memorySize,
+		bookmarked:      e.bookmarked,
 	}
 	return eventCopy
 }
@@ -100,37 +142,57 @@ type LogView struct {
 	current    *logEventLine
 	eventCount uint
 	eventLimit uint
+	index      *eventIndex
+
+	maxMemory   uint64
+	memoryUsage uint64
+	maxEventAge time.Duration
+
+	filter       func(event *LogEvent) bool
+	minLevel     LogLevel
+	visibleCount uint
+
+	searchPattern    *regexp2.Regexp
+	searchStyle      tcell.Style
+	searchMatchCount uint
 
 	newEventMatcher   *regexp.Regexp
 	concatenateEvents bool
 
 	highlightingEnabled bool
 	highlightPattern    *regexp2.Regexp
+	highlightRules      []*highlightRule
+	highlightStyles     map[string]tcell.Style
+	rulesAdded          int
 
 	highlightLevels bool
-	warningBgColor  tcell.Color
-	errorBgColor    tcell.Color
+	levelColors     map[LogLevel]levelColors
 
 	highlightCurrent bool
 	currentBgColor   tcell.Color
 
-	sourceStyle    tcell.Style
-	timestampStyle tcell.Style
-
 	// as new events are appended, older events are scrolled up, like tail -f
 	following bool
 
-	showSource       bool
-	sourceClipLength int
-	showTimestamp    bool
-	timestampFormat  string
-	wrap             bool
+	columns         []*column
+	shownColumns    []*column
+	columnSeparator rune
+	timestampFormat string
+	wrap            bool
+	wrapMode        WrapMode
+	wrapIndent      int
+	wrapMarker      rune
+	tabWidth        int
+	ansiMode        AnsiMode
+
+	horizontalOffset int
 
 	defaultStyle tcell.Style
 
 	hasFocus bool
 
 	lastWidth, lastHeight int
+	lastPageWidth         int
 	pageHeight, pageWidth int
 	fullPageWidth         int
 	screenCoords          []int
@@ -140,6 +202,32 @@ type LogView struct {
 	// force re-wrapping on next draw
 	forceWrap bool
 
+	// generations of highlighting and wrapping settings, incremented when events have to be recalculated
+	colorGeneration   uint
+	wrapGeneration    uint
+	refreshing        bool
+	onRefreshProgress OnRefreshProgress
+	// serializes the progress listener calls, see LogView.notifyRefreshProgress
+	progressLock  sync.Mutex
+	lazy          bool
+	lazyLookahead int
+
+	// events evicted by the event limit, see SetSpillFile
+	spill *spillStore
+
+	// sorted sequence numbers of bookmarked events and named marks
+	bookmarks     []uint64
+	marks         map[rune]uint64
+	markAction    markAction
+	bookmarkColor tcell.Color
+
+	selection          selection
+	selectionBgColor   tcell.Color
+	dragging           bool
+	onSelectionChanged OnSelectionChanged
+	// screen the log view was last drawn on, used to set the clipboard
+	screen tcell.Screen
+
 	sync.RWMutex
 }
 
@@ -148,19 +236,24 @@ func NewLogView() *LogView {
 	defaultStyle := tcell.StyleDefault.Foreground(gui.Styles.PrimaryTextColor).Background(gui.Styles.PrimitiveBackgroundColor)
 	logView := &LogView{
 		Box:                 gui.NewBox(),
-		showSource:          false,
-		showTimestamp:       false,
+		index:               newEventIndex(0),
+		columns:             defaultColumns(defaultStyle, "15:04:05.000"),
+		columnSeparator:     '|',
 		timestampFormat:     "15:04:05.000",
-		sourceClipLength:    6,
+		tabWidth:            4,
 		wrap:                true,
 		following:           true,
 		highlightingEnabled: true,
 		defaultStyle:        defaultStyle,
 		currentBgColor:      tcell.ColorDimGray,
-		warningBgColor:      tcell.ColorSaddleBrown,
-		errorBgColor:        tcell.ColorIndianRed,
-		sourceStyle:         defaultStyle.Foreground(tcell.ColorDarkGoldenrod),
-		timestampStyle:      defaultStyle.Foreground(tcell.ColorDarkOrange),
+		bookmarkColor:       tcell.ColorAqua,
+		selectionBgColor:    tcell.ColorDarkSlateBlue,
+		levelColors:         defaultLevelColors(),
+		highlightStyles:     make(map[string]tcell.Style),
+		colorGeneration:     1,
+		wrapGeneration:      1,
+		minLevel:            LogLevelAll,
+		searchStyle:         tcell.StyleDefault.Foreground(tcell.ColorBlack).Background(tcell.ColorGold),
 		screenCoords:        make([]int, 2),
 		concatenateEvents:   false,
 		newEventMatcher:     regexp.MustCompile(`^[^\s]`),
@@ -187,15 +280,18 @@ func (lv *LogView) GetHeight() int {
 
 // Clear deletes all events from the log view
 func (lv *LogView) Clear() {
+	defer lv.fireOnSelectionChange(lv.getSelection())
 	defer lv.fireOnCurrentChange(lv.current)
 	lv.Lock()
 	defer lv.Unlock()
 
-	lv.firstEvent = nil
-	lv.lastEvent = nil
-	lv.current = nil
-	lv.top = nil
-	lv.eventCount = 0
+	if lv.spill != nil {
+		lv.spill.reset()
+	}
+	lv.dropEvents(0)
+	lv.bookmarks = nil
+	lv.marks = nil
+	lv.selection = selection{}
 }
 
 // GetEventCount returns number of events in the log view
@@ -207,6 +303,7 @@ func (lv *LogView) GetEventCount() uint {
 
 // SetMaxEvents sets a maximum number of events that log view will hold
 func (lv *LogView) SetMaxEvents(limit uint) {
+	defer lv.fireOnSelectionChange(lv.getSelection())
 	lv.Lock()
 	defer lv.Unlock()
 
@@ -231,6 +328,33 @@ func (lv *LogView) SetLineWrap(enabled bool) {
 		lv.forceWrap = true
 	}
 	lv.wrap = enabled
+	if enabled {
+		lv.horizontalOffset = 0
+	}
+}
+
+// SetTabWidth sets the distance between tab stops. Tab characters in event messages are replaced with spaces up to
+// the next tab stop, changing the width updates the events that are already in the log view. Original messages with
+// tabs are still returned by GetCurrentEvent, GetSelectedEvents and the other getters. Zero disables the expansion.
+//
+// Default is 4
+func (lv *LogView) SetTabWidth(width int) {
+	lv.Lock()
+	defer lv.Unlock()
+
+	if lv.tabWidth == width {
+		return
+	}
+	lv.tabWidth = width
+	lv.reparseMessages()
+}
+
+// GetTabWidth returns the distance between tab stops
+func (lv *LogView) GetTabWidth() int {
+	lv.RLock()
+	defer lv.RUnlock()
+
+	return lv.tabWidth
 }
 
 // IsLineWrapEnabled returns the current status of line wrap
@@ -307,18 +431,12 @@ func (lv *LogView) SetTextStyle(style tcell.Style) {
 
 // SetSourceStyle sets the style for displaying event source
 func (lv *LogView) SetSourceStyle(style tcell.Style) {
-	lv.Lock()
-	defer lv.Unlock()
-
-	lv.sourceStyle = style
+	lv.SetColumnStyle(ColumnSource, style)
 }
 
 // SetTimestampStyle sets the style for displaying event timestamp
 func (lv *LogView) SetTimestampStyle(style tcell.Style) {
-	lv.Lock()
-	defer lv.Unlock()
-
-	lv.timestampStyle = style
+	lv.SetColumnStyle(ColumnTimestamp, style)
 }
 
 // SetCurrentBgColor sets the background color to highlight currently selected event
@@ -333,6 +451,7 @@ func (lv *LogView) SetCurrentBgColor(color tcell.Color) {
 //
 // To disable limit set it to zero.
 func (lv *LogView) SetEventLimit(limit uint) {
+	defer lv.fireOnSelectionChange(lv.getSelection())
 	lv.Lock()
 	defer lv.Unlock()
 
@@ -345,12 +464,14 @@ func (lv *LogView) SetEventLimit(limit uint) {
 // LogView calculates highlight spans once for each event when the event is appended. Any changes in highlighting
 // will not be applied to the events that are already in the log view.
 // To apply changes to all the events call this function.
-// Warning: this might be a rather expensive operation
+//
+// Events displayed on the page are updated immediately, large buffers are updated in background,
+// see SetOnRefreshProgress
 func (lv *LogView) RefreshHighlights() {
 	lv.Lock()
 	defer lv.Unlock()
 
-	lv.recolorizeLines()
+	lv.invalidateHighlights()
 }
 
 // SetHighlightPattern sets new regular expression pattern to find spans that need to be highlighted
@@ -369,7 +490,8 @@ func (lv *LogView) SetHighlightPattern(pattern string) {
 	lv.highlightPattern = regexp2.MustCompile(pattern, regexp2.IgnoreCase+regexp2.RE2)
 }
 
-// SetHighlighting enables/disables event message highlighting according to the pattern set by SetHighlightPattern.
+// SetHighlighting enables/disables event message highlighting according to the pattern set by SetHighlightPattern
+// and the highlight rules.
 //
 // Events appended when this setting was disabled will not be highlighted until RefreshHighlights function is called.
 func (lv *LogView) SetHighlighting(enable bool) {
@@ -393,10 +515,7 @@ func (lv *LogView) IsHighlightingEnabled() bool {
 // Changing warning color will do nothing to the events that are already in the log view. To update
 // highlighting of all events use RefreshHighlights. Be warned: this is an expensive operation
 func (lv *LogView) SetWarningBgColor(bgColor tcell.Color) {
-	lv.Lock()
-	defer lv.Unlock()
-
-	lv.warningBgColor = bgColor
+	lv.SetLevelBgColor(LogLevelWarning, bgColor)
 }
 
 // SetErrorColor sets the background color for events with level == LogLevelError.
@@ -405,10 +524,46 @@ func (lv *LogView) SetWarningBgColor(bgColor tcell.Color) {
 // Changing error color will do nothing to the events that are already in the log view. To update
 // highlighting of all events use RefreshHighlights. Be warned: this is an expensive operation
 func (lv *LogView) SetErrorBgColor(bgColor tcell.Color) {
+	lv.SetLevelBgColor(LogLevelError, bgColor)
+}
+
+// SetLevelBgColor sets the background color for events with a given level. tcell.ColorDefault disables the
+// background highlighting for the level.
+// Event level highlighting can be turned on and off with SetLevelHighlighting function.
+//
+// Changing level color will do nothing to the events that are already in the log view. To update
+// highlighting of all events use RefreshHighlights. Be warned: this is an expensive operation
+func (lv *LogView) SetLevelBgColor(level LogLevel, bgColor tcell.Color) {
+	lv.Lock()
+	defer lv.Unlock()
+
+	colors := lv.levelColors[level]
+	colors.bg = bgColor
+	lv.levelColors[level] = colors
+}
+
+// SetLevelFgColor sets the text color for events with a given level. tcell.ColorDefault disables the
+// text color highlighting for the level. Parts of the message highlighted with SetHighlightPattern keep their colors.
+// Event level highlighting can be turned on and off with SetLevelHighlighting function.
+//
+// Changing level color will do nothing to the events that are already in the log view. To update
+// highlighting of all events use RefreshHighlights. Be warned: this is an expensive operation
+func (lv *LogView) SetLevelFgColor(level LogLevel, fgColor tcell.Color) {
 	lv.Lock()
 	defer lv.Unlock()
 
-	lv.errorBgColor = bgColor
+	colors := lv.levelColors[level]
+	colors.fg = fgColor
+	lv.levelColors[level] = colors
+}
+
+// GetLevelColors returns the text and background colors for events with a given level
+func (lv *LogView) GetLevelColors(level LogLevel) (fgColor tcell.Color, bgColor tcell.Color) {
+	lv.RLock()
+	defer lv.RUnlock()
+
+	colors := lv.levelColors[level]
+	return colors.fg, colors.bg
 }
 
 // SetLevelHighlighting enables background color highlighting for events based on severity level
@@ -461,12 +616,14 @@ func (lv *LogView) FindMatchingEvent(startingEventId string, predicate func(even
 	defer lv.Unlock()
 
 	event := lv.findByEventId(startingEventId)
-	for event != nil {
-		logEvent := event.AsLogEvent()
+	if event == nil {
+		return nil
+	}
+	for i := lv.index.position(event); i < lv.index.count; i++ {
+		logEvent := lv.index.at(i).AsLogEvent()
 		if predicate(logEvent) {
 			return logEvent
 		}
-		event = event.next
 	}
 	return nil
 }
@@ -493,6 +650,14 @@ func (lv *LogView) Focus(_ func(p gui.Primitive)) {
 	lv.hasFocus = true
 }
 
+// Blur is called when this primitive loses focus.
+func (lv *LogView) Blur() {
+	lv.Lock()
+	defer lv.Unlock()
+
+	lv.hasFocus = false
+}
+
 // HasFocus returns whether or not this primitive has focus.
 func (lv *LogView) HasFocus() bool {
 	lv.RLock()
@@ -511,9 +676,16 @@ func (lv *LogView) Draw(screen tcell.Screen) {
 
 	lv.Box.Draw(screen)
 
+	defer lv.fireOnSelectionChange(lv.getSelection())
+	defer lv.fireOnCurrentChange(lv.current)
 	lv.Lock()
 	defer lv.Unlock()
 
+	// events get older without new events appended
+	if lv.maxEventAge > 0 {
+		lv.ensureEventLimit()
+	}
+
 	// Get the available size.
 	x, y, width, height := lv.GetInnerRect()
 	if height == 0 {
@@ -521,22 +693,24 @@ func (lv *LogView) Draw(screen tcell.Screen) {
 	}
 	lv.screenCoords[0] = x
 	lv.screenCoords[1] = y
+	lv.screen = screen
 
 	lv.fullPageWidth = width
 	lv.pageHeight = height
-	lv.pageWidth = width
-	if lv.isHeaderPossible() {
-		lv.pageWidth -= lv.headerWidth()
-	}
-	if (width != lv.lastWidth || height != lv.lastHeight && lv.wrap) || lv.forceWrap {
+	lv.layoutColumns()
+	lv.pageWidth = width - lv.headerWidth()
+	if (width != lv.lastWidth || height != lv.lastHeight && lv.wrap) || lv.pageWidth != lv.lastPageWidth || lv.forceWrap {
 		lv.forceWrap = false
-		lv.rewrapLines()
+		lv.invalidateWrap()
 		if lv.following {
 			// ensure correct top line when resizing
 			lv.scrollToEnd()
 		}
+	} else {
+		// events on the page might not be refreshed yet if refresh is running in background
+		lv.preparePage()
 	}
-	lv.lastWidth, lv.lastHeight = width, height
+	lv.lastWidth, lv.lastHeight, lv.lastPageWidth = width, height, lv.pageWidth
 
 	line := y
 
@@ -544,7 +718,7 @@ func (lv *LogView) Draw(screen tcell.Screen) {
 	for top != nil && line < y+height {
 		lv.drawEvent(screen, x, line, top)
 		line++
-		top = top.next
+		top = lv.nextVisible(top)
 	}
 	for line < y+height {
 		lv.clearLine(screen, x, line)
@@ -555,21 +729,33 @@ func (lv *LogView) Draw(screen tcell.Screen) {
 // AppendEvent appends an event to the log view
 // If possible use AppendEvents to add multiple events at once
 func (lv *LogView) AppendEvent(logEvent *LogEvent) {
+	defer lv.fireOnSelectionChange(lv.getSelection())
 	defer lv.fireOnCurrentChange(lv.current)
 	lv.Lock()
 	defer lv.Unlock()
 
-	lv.append(logEvent)
+	event := lv.append(logEvent)
+	if lv.lazy {
+		lv.followEvent(lv.linkedOrLast(event))
+	}
 }
 
 // AppendEvents appends multiple events in a single batch improving performance
 func (lv *LogView) AppendEvents(events []*LogEvent) {
+	defer lv.fireOnSelectionChange(lv.getSelection())
 	defer lv.fireOnCurrentChange(lv.current)
 	lv.Lock()
 	defer lv.Unlock()
 
+	var lastVisible *logEventLine
 	for _, e := range events {
-		lv.append(e)
+		if event := lv.append(e); event != nil && !event.filteredOut {
+			lastVisible = event
+		}
+	}
+	if lv.lazy && len(events) > 0 {
+		// top position is updated once per batch, so the events that scroll out of the page are never processed
+		lv.followEvent(lv.linkedOrLast(lastVisible))
 	}
 }
 
@@ -648,8 +834,9 @@ func (lv *LogView) IsFollowing() bool {
 	return lv.following
 }
 
-// ScrollToTimestamp scrolls to the first event with a timestamp equal to or greater than given.
-// If no event satisfies that condition it will not scroll and return false.
+// ScrollToTimestamp scrolls to the event with the earliest timestamp equal to or greater than given, even if events
+// were appended out of order. Events hidden by the filter are skipped. If no event satisfies that condition it will
+// not scroll and return false.
 //
 // Current event will be updated to the found event
 func (lv *LogView) ScrollToTimestamp(timestamp time.Time) bool {
@@ -657,26 +844,21 @@ func (lv *LogView) ScrollToTimestamp(timestamp time.Time) bool {
 	lv.Lock()
 	defer lv.Unlock()
 
-	event := lv.firstEvent
-	for event != nil && event.Timestamp.Before(timestamp) {
-		event = event.next
+	event := lv.index.firstAtOrAfter(timestamp, func(event *logEventLine) bool {
+		return !event.filteredOut
+	})
+	if seq, found := lv.spilledAtOrAfter(timestamp, event); found {
+		event = lv.showEvent(seq)
 	}
 	if event == nil {
 		return false
 	}
-	lv.top = event
-	lv.current = event
-	lv.adjustTop()
-	for i := 0; i < lv.pageHeight/4; i++ { // scroll a little bit back
-		if lv.top.previous != nil {
-			lv.top = lv.top.previous
-		}
-	}
+	lv.moveTo(event)
 	return true
 }
 
 // ScrollToEventID scrolls to the first event with a matching eventID
-// If no such event is found it will not scroll and return false.
+// If no such event is found or the event is hidden by the filter it will not scroll and return false.
 //
 // Current event will be updated to the found event
 func (lv *LogView) ScrollToEventID(eventID string) bool {
@@ -685,17 +867,10 @@ func (lv *LogView) ScrollToEventID(eventID string) bool {
 	defer lv.Unlock()
 
 	event := lv.findByEventId(eventID)
-	if event == nil {
+	if event == nil || event.filteredOut {
 		return false
 	}
-	lv.top = event
-	lv.current = event
-	lv.adjustTop()
-	for i := 0; i < lv.pageHeight/4; i++ {
-		if lv.top.previous != nil {
-			lv.top = lv.top.previous
-		}
-	}
+	lv.moveTo(event)
 	return true
 }
 
@@ -716,20 +891,15 @@ func (lv *LogView) SelectPrevEvent() {
 // SetShowSource enables/disables the displaying of event source
 //
 // Event Source is displayed to the left of the actual event message with style defined by SetSourceStyle and
-// is clipped to the length set by SetSourceClipLength (6 characters is the default)
+// is clipped to the length set by SetSourceClipLength (6 characters is the default).
+// This is a shortcut for SetColumnVisible(ColumnSource, enabled)
 func (lv *LogView) SetShowSource(enabled bool) {
-	lv.Lock()
-	defer lv.Unlock()
-
-	lv.showSource = enabled
+	lv.SetColumnVisible(ColumnSource, enabled)
 }
 
 // IsShowSource returns whether the showing of event source is enabled
 func (lv *LogView) IsShowSource() bool {
-	lv.RLock()
-	defer lv.RUnlock()
-
-	return lv.showSource
+	return lv.IsColumnVisible(ColumnSource)
 }
 
 // SetSourceClipLength sets the maximum length of event source that would be displayed if SetShowSource is on
@@ -737,7 +907,7 @@ func (lv *LogView) SetSourceClipLength(length int) {
 	lv.Lock()
 	defer lv.Unlock()
 
-	lv.sourceClipLength = length
+	lv.findColumn(ColumnSource).width = length
 }
 
 // GetSourceClipLength returns the current maximum length of event source that would be displayed
@@ -745,28 +915,24 @@ func (lv *LogView) GetSourceClipLength() int {
 	lv.RLock()
 	defer lv.RUnlock()
 
-	return lv.sourceClipLength
+	return lv.findColumn(ColumnSource).width
 }
 
 // SetShowTimestamp enables/disables the displaying of event timestamp
 //
-// Event timestamp is displayed to the left of the actual event message with the format defined by SetTimestampFormat
+// Event timestamp is displayed to the left of the actual event message with the format defined by SetTimestampFormat.
+// This is a shortcut for SetColumnVisible(ColumnTimestamp, enabled)
 func (lv *LogView) SetShowTimestamp(enabled bool) {
-	lv.Lock()
-	defer lv.Unlock()
-
-	lv.showTimestamp = enabled
+	lv.SetColumnVisible(ColumnTimestamp, enabled)
 }
 
 // IsShowTimestamp returns whether the showing of event source is enabled
 func (lv *LogView) IsShowTimestamp() bool {
-	lv.RLock()
-	defer lv.RUnlock()
-
-	return lv.showTimestamp
+	return lv.IsColumnVisible(ColumnTimestamp)
 }
 
-// SetTimestampFormat sets the format for displaying the event timestamp.
+// SetTimestampFormat sets the format for displaying the event timestamp. Width of the timestamp column is set to
+// the length of the format, unless the column is auto-sized.
 //
 // Default is 15:04:05.000
 func (lv *LogView) SetTimestampFormat(format string) {
@@ -774,6 +940,12 @@ func (lv *LogView) SetTimestampFormat(format string) {
 	defer lv.Unlock()
 
 	lv.timestampFormat = format
+	c := lv.findColumn(ColumnTimestamp)
+	if c.widthMode == ColumnWidthAuto {
+		lv.measureColumn(c)
+	} else {
+		c.width = len(format)
+	}
 }
 
 // GetTimestampFormat returns the format used to display timestamps
@@ -787,10 +959,14 @@ func (lv *LogView) GetTimestampFormat() string {
 // InputHandler returns the handler for this primitive.
 func (lv *LogView) InputHandler() func(event *tcell.EventKey, setFocus func(p gui.Primitive)) {
 	return lv.WrapInputHandler(func(event *tcell.EventKey, setFocus func(p gui.Primitive)) {
+		defer lv.fireOnSelectionChange(lv.getSelection())
 		defer lv.fireOnCurrentChange(lv.current)
 		lv.Lock()
 		defer lv.Unlock()
 
+		if lv.handleMarkKey(event) {
+			return
+		}
 		if HitShortcut(event, Keys.MoveFirst, Keys.MoveFirst2) {
 			lv.scrollToStart()
 		} else if HitShortcut(event, Keys.MoveLast, Keys.MoveLast2) {
@@ -803,6 +979,34 @@ func (lv *LogView) InputHandler() func(event *tcell.EventKey, setFocus func(p gu
 			lv.scrollPageUp()
 		} else if HitShortcut(event, Keys.MoveNextPage) {
 			lv.scrollPageDown()
+		} else if HitShortcut(event, Keys.SearchNext) {
+			lv.search(true)
+		} else if HitShortcut(event, Keys.SearchPrev) {
+			lv.search(false)
+		} else if HitShortcut(event, Keys.CycleMinLevel) {
+			lv.cycleMinLevel()
+		} else if HitShortcut(event, Keys.MoveLeft, Keys.MoveLeft2) {
+			lv.scrollHorizontally(-horizontalScrollStep)
+		} else if HitShortcut(event, Keys.MoveRight, Keys.MoveRight2) {
+			lv.scrollHorizontally(horizontalScrollStep)
+		} else if HitShortcut(event, Keys.ToggleBookmark) {
+			lv.toggleBookmark()
+		} else if HitShortcut(event, Keys.NextBookmark) {
+			lv.gotoBookmark(true)
+		} else if HitShortcut(event, Keys.PrevBookmark) {
+			lv.gotoBookmark(false)
+		} else if HitShortcut(event, Keys.SetMark) {
+			lv.markAction = markSet
+		} else if HitShortcut(event, Keys.JumpToMark) {
+			lv.markAction = markJump
+		} else if HitShortcut(event, Keys.SelectUp) {
+			lv.extendSelection(false)
+		} else if HitShortcut(event, Keys.SelectDown) {
+			lv.extendSelection(true)
+		} else if HitShortcut(event, Keys.CopySelection) {
+			lv.copySelection()
+		} else if HitShortcut(event, Keys.Cancel) {
+			lv.selection.active = false
 		}
 	})
 }
@@ -811,11 +1015,37 @@ func (lv *LogView) InputHandler() func(event *tcell.EventKey, setFocus func(p gu
 func (lv *LogView) MouseHandler() func(action gui.MouseAction, event *tcell.EventMouse, setFocus func(p gui.Primitive)) (consumed bool, capture gui.Primitive) {
 	return lv.WrapMouseHandler(func(action gui.MouseAction, event *tcell.EventMouse, setFocus func(p gui.Primitive)) (consumed bool, capture gui.Primitive) {
 		x, y := event.Position()
-		if !lv.InRect(x, y) {
+		lv.RLock()
+		dragging := lv.dragging
+		lv.RUnlock()
+		if !lv.InRect(x, y) && !dragging {
 			return false, nil
 		}
 
 		switch action {
+		case gui.MouseLeftDown:
+			defer lv.fireOnSelectionChange(lv.getSelection())
+			consumed, capture = true, lv
+			setFocus(lv)
+			lv.Lock()
+			lv.dragging = true
+			lv.startSelection(lv.atOffset(lv.top, y-lv.screenCoords[1]))
+			lv.Unlock()
+		case gui.MouseMove:
+			if dragging {
+				defer lv.fireOnSelectionChange(lv.getSelection())
+				defer lv.fireOnCurrentChange(lv.current)
+				consumed, capture = true, lv
+				lv.Lock()
+				lv.following = false
+				lv.dragSelection(y - lv.screenCoords[1])
+				lv.Unlock()
+			}
+		case gui.MouseLeftUp:
+			lv.Lock()
+			lv.dragging = false
+			lv.Unlock()
+			consumed = dragging
 		case gui.MouseLeftClick:
 			defer lv.fireOnCurrentChange(lv.current)
 			consumed = true
@@ -828,10 +1058,24 @@ func (lv *LogView) MouseHandler() func(action gui.MouseAction, event *tcell.Even
 				lv.onCurrentChanged(lv.current.AsLogEvent())
 			}
 		case gui.MouseScrollUp:
-			lv.ScrollPageUp()
+			if event.Modifiers()&tcell.ModShift != 0 {
+				lv.ScrollLeft()
+			} else {
+				lv.ScrollPageUp()
+			}
 			consumed = true
 		case gui.MouseScrollDown:
-			lv.ScrollPageDown()
+			if event.Modifiers()&tcell.ModShift != 0 {
+				lv.ScrollRight()
+			} else {
+				lv.ScrollPageDown()
+			}
+			consumed = true
+		case gui.MouseScrollLeft:
+			lv.ScrollLeft()
+			consumed = true
+		case gui.MouseScrollRight:
+			lv.ScrollRight()
 			consumed = true
 		}
 
@@ -858,54 +1102,153 @@ func (lv *LogView) fireOnCurrentChange(oldCurrent *logEventLine) {
 	}
 }
 
-func (lv *LogView) append(logEvent *LogEvent) {
+func (lv *LogView) append(logEvent *LogEvent) *logEventLine {
 	var event *logEventLine
 
-	if !lv.concatenateEvents || lv.newEventMatcher == nil || lv.newEventMatcher.MatchString(logEvent.Message) || lv.lastEvent == nil {
+	message, ansiSpans := lv.parseMessage(logEvent.Message)
+
+	continuation := lv.concatenateEvents && lv.newEventMatcher != nil && !lv.newEventMatcher.MatchString(string(message))
+	if lv.isDetached() && lv.appendDetached(logEvent, message, ansiSpans, continuation) {
+		return nil
+	}
+
+	if !continuation || lv.lastEvent == nil {
 		// defensive copy of Log event
-		event = &logEventLine{
-			EventID:     logEvent.EventID,
-			Source:      logEvent.Source,
-			Timestamp:   logEvent.Timestamp,
-			Level:       logEvent.Level,
-			Runes:       []rune(logEvent.Message),
-			lineCount:   1,
-			lineID:      lv.eventCount + 1,
-			start:       0,
-			order:       0,
-			end:         utf8.RuneCountInString(logEvent.Message),
-			hasNewLines: strings.Contains(logEvent.Message, "\n"),
-		}
+		event = newEventLine(logEvent, message, ansiSpans, lv.eventCount+1)
+		event.filteredOut = !lv.matchesFilter(event)
 		lv.insertAfter(lv.lastEvent, event, true)
 	} else {
 		event = lv.lastEvent
-		event.Runes = append(event.Runes, []rune("\n"+logEvent.Message)...)
-		event.hasNewLines = event.hasNewLines || strings.Contains(logEvent.Message, "\n")
+		concatenateMessage(event, logEvent.Message, message, ansiSpans)
 		event = lv.mergeWrappedLines(event)
+		lv.setFilteredOut(event, !lv.matchesFilter(event))
 	}
 
-	// process event
-	lv.colorize(event)
-	lv.calculateWrap(event)
+	event = lv.processEvent(event)
 
 	lv.ensureEventLimit()
 
-	// if we're in following mode and have enough events to fill the page then update the top position
-	if lv.following && lv.eventCount >= uint(lv.pageHeight) {
-		lv.top = lv.atOffset(lv.lastEvent, -lv.pageHeight+1)
-		lv.current = event
+	if !lv.lazy {
+		lv.followEvent(event)
+	}
+	return event
+}
+
+// newEventLine creates an unwrapped event line with a defensive copy of the log event
+func newEventLine(logEvent *LogEvent, message []rune, ansiSpans []ansiSpan, lineID uint) *logEventLine {
+	event := &logEventLine{
+		EventID:     logEvent.EventID,
+		Source:      logEvent.Source,
+		Timestamp:   logEvent.Timestamp,
+		Level:       logEvent.Level,
+		Fields:      copyFields(logEvent.Fields),
+		Runes:       message,
+		ansiSpans:   ansiSpans,
+		lineCount:   1,
+		lineID:      lineID,
+		start:       0,
+		order:       0,
+		end:         len(message),
+		hasNewLines: strings.Contains(logEvent.Message, "\n"),
+	}
+	if needsOriginal(logEvent.Message) {
+		event.original = logEvent.Message
+	}
+	return event
+}
+
+// concatenateMessage appends the message to the event on a new line. original is the message before it was parsed
+func concatenateMessage(event *logEventLine, original string, message []rune, ansiSpans []ansiSpan) {
+	if event.original != "" || needsOriginal(original) {
+		event.original = event.originalMessage() + "\n" + original
+	}
+	offset := len(event.Runes) + 1
+	for _, span := range ansiSpans {
+		event.ansiSpans = append(event.ansiSpans, ansiSpan{start: span.start + offset, end: span.end + offset, style: span.style})
+	}
+	event.Runes = append(append(event.Runes, '\n'), message...)
+	event.hasNewLines = event.hasNewLines || strings.Contains(original, "\n")
+}
+
+// needsOriginal returns true if the parsed message may differ from the original one, see LogView.parseMessage
+func needsOriginal(message string) bool {
+	return strings.ContainsAny(message, "\t\x1b")
+}
+
+// parseMessage expands tabs and processes escape sequences in the message according to the current settings
+func (lv *LogView) parseMessage(message string) ([]rune, []ansiSpan) {
+	if lv.ansiMode == AnsiRaw {
+		return expandTabs(message, lv.tabWidth), nil
+	}
+	return parseAnsi(message, lv.tabWidth, lv.ansiMode == AnsiColors)
+}
+
+// reparseMessages parses the original messages of all the events again after tab width or ANSI mode was changed and
+// refreshes the events
+func (lv *LogView) reparseMessages() {
+	for event := lv.firstEvent; event != nil; event = event.next {
+		if event.original == "" {
+			continue
+		}
+		event = lv.mergeWrappedLines(event)
+		lv.reparseMessage(event)
+		lv.updateSearchMatches(event)
+	}
+	if lv.spill != nil && lv.spill.pending != nil {
+		lv.reparseMessage(lv.spill.pending)
+	}
+	lv.colorGeneration++
+	lv.invalidateWrap()
+}
+
+// reparseMessage parses the original message of the unwrapped event with the current settings
+func (lv *LogView) reparseMessage(event *logEventLine) {
+	if event.original == "" {
+		return
+	}
+	event.Runes, event.ansiSpans = lv.parseMessage(event.original)
+	event.end = len(event.Runes)
+}
+
+// processEvent measures, searches, highlights and wraps the event that was added to the list.
+// Returns the first line of the event
+func (lv *LogView) processEvent(event *logEventLine) *logEventLine {
+	lv.measureColumns(event)
+	lv.updateSearchMatches(event)
+	if lv.lazy {
+		// highlighting and wrapping are calculated when the event is displayed
+		event.colorGeneration = 0
+		event.wrapGeneration = 0
+	} else {
+		lv.colorize(event)
+		event = findFirstWrappedLine(lv.calculateWrap(event))
+	}
+	lv.updateMemoryUsage(event)
+	return event
+}
+
+// followEvent updates the top position if we're in following mode and have enough events to fill the page
+func (lv *LogView) followEvent(event *logEventLine) {
+	if lv.following && lv.visibleCount >= uint(lv.pageHeight) {
+		last := lv.lastVisible()
+		lv.top = lv.atOffset(last, -lv.pageHeight+1)
+		if !event.filteredOut {
+			lv.current = event
+		}
 	}
 }
 
 // atOffset finds event that is at given offset from the starting event
-// offset can be positive or negative
-// if first or last event is reached then it is returned
+// offset can be positive or negative, events hidden by the filter are not counted
+// if first or last visible event is reached then it is returned
+// events on the way are prepared, so the wrapped lines are counted correctly even in lazy mode
+// lines are walked one by one, so it takes time proportional to the offset, use eventIndex to jump to the event
 func (lv *LogView) atOffset(start *logEventLine, offset int) *logEventLine {
-	if offset == 0 {
+	if offset == 0 || start == nil {
 		return start
 	}
 
-	current := start
+	current := lv.prepareLine(start)
 	var steps int
 	if offset > 0 {
 		steps = offset
@@ -913,14 +1256,20 @@ func (lv *LogView) atOffset(start *logEventLine, offset int) *logEventLine {
 		steps = -offset
 	}
 	for steps > 0 {
-		if (current == lv.firstEvent && offset < 0) || (current == lv.lastEvent && offset > 0) {
-			break
-		}
+		var next *logEventLine
 		if offset < 0 {
-			current = current.previous
+			next = lv.prevPrepared(current)
 		} else {
-			current = current.next
+			next = lv.prepareLine(lv.nextVisible(current))
+		}
+		if next == nil {
+			// older or newer events may be in the spill file, they are never paged in while following
+			if !lv.following && lv.pageIn(offset < 0) {
+				continue
+			}
+			break
 		}
+		current = next
 		steps--
 	}
 	return current
@@ -931,7 +1280,8 @@ func (lv *LogView) atOffset(start *logEventLine, offset int) *logEventLine {
 // new event lines with order >= 1 are created and inserted in the log list
 // last event is returned
 func (lv *LogView) calculateWrap(event *logEventLine) *logEventLine {
-	if !lv.wrap || lv.pageWidth == 0 || (len(event.Runes) <= lv.pageWidth && !event.hasNewLines) {
+	event.wrapGeneration = lv.wrapGeneration
+	if !lv.wrap || lv.pageWidth == 0 || event.filteredOut || (runesWidth(event.Runes) <= lv.pageWidth && !event.hasNewLines) {
 		if event.order != 0 { // no wrapping needed, but the line is wrapped
 			event = lv.mergeWrappedLines(event)
 		}
@@ -943,31 +1293,24 @@ func (lv *LogView) calculateWrap(event *logEventLine) *logEventLine {
 
 	lineLength := len(event.Runes)
 	start := 0
-	end := 0
+	continued := false
 	events := make([]*logEventLine, 0)
 
-	for end < lineLength {
-		if end-start == lv.pageWidth || event.Runes[end] == '\n' { // wrap here
-			currentEvent := event.copy()
-			currentEvent.hasNewLines = false
-			currentEvent.start = start
-			if event.Runes[end] == '\n' {
-				end = end + 1
-				currentEvent.hasNewLines = true
-			}
-			currentEvent.end = end
-
-			start = end
-			events = append(events, currentEvent)
-		} else {
-			end++
+	for start < lineLength {
+		width := lv.pageWidth
+		if continued {
+			width = maxInt(width-lv.wrapIndentWidth(), 1)
 		}
-	}
-	if end > start { // add final piece
+		end, newLine := lv.findLineEnd(event.Runes, start, width)
 		currentEvent := event.copy()
 		currentEvent.start = start
 		currentEvent.end = end
+		currentEvent.hasNewLines = newLine
+		currentEvent.continued = continued
 		events = append(events, currentEvent)
+
+		continued = !newLine
+		start = end
 	}
 	for i, r := range events {
 		r.order = i + 1
@@ -983,6 +1326,13 @@ func findFirstWrappedLine(event *logEventLine) *logEventLine {
 	return event
 }
 
+func findLastWrappedLine(event *logEventLine) *logEventLine {
+	for event.next != nil && event.next.order > 1 {
+		event = event.next
+	}
+	return event
+}
+
 // mergeWrappedLines will delete all extra lines for an event
 // if the event order is == 0, it will return the event
 // otherwise it will fine the first event, change its order to 0
@@ -997,6 +1347,8 @@ func (lv *LogView) mergeWrappedLines(event *logEventLine) *logEventLine {
 	event.start = 0
 	event.lineCount = 1
 	event.end = len(event.Runes)
+	event.hasNewLines = containsNewLine(event.Runes)
+	event.continued = false
 	next := event.next
 	if next == lv.lastEvent {
 		lv.lastEvent = event
@@ -1032,8 +1384,6 @@ func (lv *LogView) insertAfter(node *logEventLine, new *logEventLine, adjustLine
 	if node == nil {
 		lv.firstEvent = new
 		lv.lastEvent = new
-		lv.top = new
-		lv.current = new
 	} else {
 		new.previous = node
 		new.next = node.next
@@ -1045,12 +1395,60 @@ func (lv *LogView) insertAfter(node *logEventLine, new *logEventLine, adjustLine
 			lv.lastEvent = new
 		}
 	}
+	if lv.top == nil && !new.filteredOut {
+		lv.top = new
+		lv.current = new
+	}
 	if adjustLineCount {
 		lv.eventCount++
+		lv.index.add(new)
+		if !new.filteredOut {
+			lv.visibleCount++
+			if len(new.searchMatches) > 0 {
+				lv.searchMatchCount++
+			}
+		}
 	}
 	return new
 }
 
+// insertFirst inserts the event before the first event, it gets the sequence number preceding the first event
+func (lv *LogView) insertFirst(new *logEventLine) *logEventLine {
+	new.next = lv.firstEvent
+	if lv.firstEvent != nil {
+		lv.firstEvent.previous = new
+	} else {
+		lv.lastEvent = new
+	}
+	lv.firstEvent = new
+	if lv.top == nil && !new.filteredOut {
+		lv.top = new
+		lv.current = new
+	}
+	lv.eventCount++
+	lv.index.prepend(new)
+	if !new.filteredOut {
+		lv.visibleCount++
+		if len(new.searchMatches) > 0 {
+			lv.searchMatchCount++
+		}
+	}
+	return new
+}
+
+// dropEvents removes all the events from memory, the next appended event gets a given sequence number
+func (lv *LogView) dropEvents(firstSeq uint64) {
+	lv.firstEvent = nil
+	lv.lastEvent = nil
+	lv.current = nil
+	lv.top = nil
+	lv.eventCount = 0
+	lv.memoryUsage = 0
+	lv.index = newEventIndex(firstSeq)
+	lv.visibleCount = 0
+	lv.searchMatchCount = 0
+}
+
 func (lv *LogView) deleteEvent(event *logEventLine, adjustLineCount bool) {
 	if event == nil {
 		return
@@ -1068,21 +1466,21 @@ func (lv *LogView) deleteEvent(event *logEventLine, adjustLineCount bool) {
 		lv.lastEvent = event.previous
 	}
 	if event == lv.top {
-		if event.next == nil {
-			lv.top = event.previous
-		} else {
-			lv.top = event.previous
-		}
+		lv.top = lv.visibleNeighbour(event)
 	}
 	if event == lv.current {
-		if event.next == nil {
-			lv.current = event.previous
-		} else {
-			lv.current = event.previous
-		}
+		lv.current = lv.visibleNeighbour(event)
 	}
 	if adjustLineCount {
 		lv.eventCount--
+		lv.memoryUsage -= event.memorySize
+		lv.index.remove(event)
+		if !event.filteredOut {
+			lv.visibleCount--
+			if len(event.searchMatches) > 0 {
+				lv.searchMatchCount--
+			}
+		}
 	}
 }
 
@@ -1110,6 +1508,7 @@ func (lv *LogView) replaceEvent(toReplace *logEventLine, replacement []*logEvent
 	if toReplace == lv.firstEvent {
 		lv.firstEvent = replacement[0]
 	}
+	lv.index.replace(toReplace, replacement[0])
 	if toReplace == lv.lastEvent {
 		lv.lastEvent = replacement[lastI]
 	}
@@ -1138,149 +1537,73 @@ func (lv *LogView) unwrapLines() {
 	}
 }
 
-// recolorizeLines calculates spans for strings
-// This must be called after unwrapLines, otherwise it may panic on any wrapped line
-func (lv *LogView) recolorizeLines() {
-	event := lv.firstEvent
-	for event != nil {
-		lv.colorize(event)
-		event = event.next
-	}
-}
-
-// rewrapLines recalculates string wrapping
-func (lv *LogView) rewrapLines() {
-	event := lv.firstEvent
-	for event != nil {
-		isTop := event == lv.top
-		event = lv.calculateWrap(event)
-		if isTop {
-			lv.top = event
-		}
-		event = event.next
-	}
-}
-
 // drawEvent draws single event on a single line
 func (lv *LogView) drawEvent(screen tcell.Screen, x int, y int, event *logEventLine) {
-	if lv.showSource && lv.isHeaderPossible() {
-		if event.order <= 1 {
-			x = lv.printSource(screen, x, y, event) + 1
-		} else {
-			x += lv.sourceHeaderWidth()
-		}
-	}
-	if lv.showTimestamp && lv.isHeaderPossible() {
-		if event.order <= 1 {
-			x = lv.printTimestamp(screen, x, y, event) + 1
-		} else {
-			x += lv.timestampHeaderWidth()
-		}
+	x = lv.drawGutter(screen, x, y, event)
+	x = lv.drawHeader(screen, x, y, event)
+	width := lv.pageWidth
+	if event.continued {
+		indent := lv.drawWrapIndent(screen, x, y, event)
+		x += indent
+		width -= indent
 	}
 
 	if lv.highlightingEnabled {
-		lv.printLogLine(screen, x, y, event)
-	} else {
-		lv.printLogLineNoHighlights(screen, x, y, event)
-	}
-}
-
-func (lv *LogView) printSource(screen tcell.Screen, x int, y int, event *logEventLine) int {
-	var source string
-	if len(event.Source) > lv.sourceClipLength {
-		source = event.Source[:lv.sourceClipLength]
-	} else {
-		source = fmt.Sprintf("%"+strconv.Itoa(lv.sourceClipLength)+"v", event.Source)
-	}
-	var style tcell.Style
-	if lv.highlightCurrent && event == lv.current {
-		style = lv.defaultStyle.Background(lv.currentBgColor)
-	} else {
-		style = lv.sourceStyle
-	}
-
-	lv.printSpecial(screen, x, y, event, source, style)
-
-	return x + len(source) + 2
-}
-
-func (lv *LogView) printTimestamp(screen tcell.Screen, x int, y int, event *logEventLine) int {
-	ts := event.Timestamp.Format(lv.timestampFormat)
-	var style tcell.Style
-	if lv.highlightCurrent && event == lv.current {
-		style = lv.defaultStyle.Background(lv.currentBgColor)
-	} else {
-		style = lv.timestampStyle
-	}
-	return lv.printSpecial(screen, x, y, event, ts, style)
-}
-
-func (lv *LogView) printSpecial(screen tcell.Screen, x int, y int, event *logEventLine, ts string, style tcell.Style) int {
-	printString(screen, x, y, ts, style)
-
-	if lv.highlightCurrent && event == lv.current {
-		style = lv.defaultStyle.Background(lv.currentBgColor)
+		lv.printLogLine(screen, x, y, width, event)
 	} else {
-		style = lv.defaultStyle
+		lv.printLogLineNoHighlights(screen, x, y, width, event)
 	}
-	printString(screen, x+len(ts)+1, y, "|", style)
-
-	return x + len(ts) + 2
 }
 
-func (lv *LogView) printLogLine(screen tcell.Screen, x int, y int, event *logEventLine) {
-	// find first styled span for the event
+func (lv *LogView) printLogLine(screen tcell.Screen, x int, y int, width int, event *logEventLine) {
+	from := lv.lineStart(event)
+	// find first styled span for the visible part of the event
 	spanIndex := 0
 	for spanIndex < len(event.styleSpans) {
-		if event.styleSpans[spanIndex].start <= event.start && event.styleSpans[spanIndex].end >= event.start {
+		if event.styleSpans[spanIndex].start <= from && event.styleSpans[spanIndex].end > from {
 			break
 		}
 		spanIndex++
 	}
 	if spanIndex == len(event.styleSpans) { // no colorization needed
-		lv.printLogLineNoHighlights(screen, x, y, event)
+		lv.printLogLineNoHighlights(screen, x, y, width, event)
 		return
 	}
-	textPos := event.start
-	i := x
+	matchIndex := 0
 	var style tcell.Style
-	for textPos < event.end {
-		style = event.styleSpans[spanIndex].style
-		if lv.highlightCurrent && event == lv.current { // overwrite bg color for current selected event
-			style = style.Background(lv.currentBgColor)
-		}
-		screen.SetCell(i, y, style, event.Runes[textPos])
-		i++
-		textPos++
-		if textPos >= event.styleSpans[spanIndex].end {
+	bg, highlighted := lv.lineBackground(event)
+	printed, cells := printRunes(screen, x, y, width, event.Runes[from:event.end], func(i int) tcell.Style {
+		textPos := from + i
+		for textPos >= event.styleSpans[spanIndex].end && spanIndex < len(event.styleSpans)-1 {
 			spanIndex++
 		}
-	}
+		style = event.styleSpans[spanIndex].style
+		if highlighted { // overwrite bg color for current or selected event
+			style = style.Background(bg)
+		}
+		return lv.applySearchStyle(event, textPos, &matchIndex, style)
+	})
 
-	for i <= x+lv.pageWidth+5 {
+	for i := x + cells; i < x+width; i++ {
 		screen.SetCell(i, y, style, ' ')
-		i++
 	}
-
+	lv.drawScrollIndicators(screen, x, y, width, event, from+printed)
 }
 
-func (lv *LogView) printLogLineNoHighlights(screen tcell.Screen, x int, y int, event *logEventLine) {
-	i := x
+func (lv *LogView) printLogLineNoHighlights(screen tcell.Screen, x int, y int, width int, event *logEventLine) {
 	style := lv.defaultStyle
-	if lv.highlightCurrent && event == lv.current { // overwrite bg color for current selected event
-		style = style.Background(lv.currentBgColor)
-	}
-	for pos := event.start; pos < event.end; pos++ {
-		screen.SetCell(i, y, style, event.Runes[pos])
-		i++
-		if i >= lv.pageWidth {
-			break
-		}
+	if bg, highlighted := lv.lineBackground(event); highlighted { // overwrite bg color for current or selected event
+		style = style.Background(bg)
 	}
-	for i <= x+lv.pageWidth {
+	matchIndex := 0
+	from := lv.lineStart(event)
+	printed, cells := printRunes(screen, x, y, width, event.Runes[from:event.end], func(i int) tcell.Style {
+		return lv.applySearchStyle(event, from+i, &matchIndex, style)
+	})
+	for i := x + cells; i < x+width; i++ {
 		screen.SetCell(i, y, style, ' ')
-		i++
 	}
+	lv.drawScrollIndicators(screen, x, y, width, event, from+printed)
 }
 
 func (lv *LogView) clearLine(screen tcell.Screen, x, line int) {
@@ -1292,27 +1615,37 @@ func (lv *LogView) clearLine(screen tcell.Screen, x, line int) {
 	}
 }
 
+// ensureEventLimit evicts the oldest events until the event limit, memory budget and age limit are satisfied. Age is
+// not checked while older events are paged in from the spill file
 func (lv *LogView) ensureEventLimit() {
-	if lv.eventLimit == 0 {
-		return
-	}
-	for lv.eventCount > lv.eventLimit {
+	for lv.firstEvent != nil && lv.exceedsLimits(!lv.isDetached()) {
 		if lv.firstEvent != nil && lv.firstEvent.order > 0 {
 			lv.mergeWrappedLines(lv.firstEvent)
 		}
+		lv.spillEvent(lv.firstEvent)
+		if lv.spill == nil || lv.spill.err != nil {
+			lv.forgetEvicted(lv.firstEvent)
+		}
 		lv.deleteEvent(lv.firstEvent, true)
 	}
 }
 
 func (lv *LogView) scrollToStart() {
-	lv.top = lv.firstEvent
-	lv.current = lv.firstEvent
+	if lv.spill != nil && lv.index.firstSeq > lv.spill.baseSeq {
+		lv.showEvent(lv.spill.baseSeq)
+	}
+	lv.top = lv.firstVisible()
+	lv.current = lv.top
 	lv.following = false
 }
 
 func (lv *LogView) scrollToEnd() {
-	lv.top = lv.atOffset(lv.lastEvent, -(lv.pageHeight - 1))
-	lv.current = lv.lastEvent
+	if lv.isDetached() {
+		lv.showEvent(lv.lastSeq())
+	}
+	last := lv.lastVisible()
+	lv.top = lv.atOffset(last, -(lv.pageHeight - 1))
+	lv.current = last
 	lv.following = true
 }
 
@@ -1326,7 +1659,7 @@ func (lv *LogView) scrollOneUp() {
 }
 
 func (lv *LogView) scrollOneDown() {
-	if lv.current == lv.lastEvent {
+	if lv.nextVisible(lv.current) == nil && !lv.pageIn(false) {
 		lv.following = true
 		return
 	}
@@ -1340,6 +1673,14 @@ func (lv *LogView) scrollOneDown() {
 	lv.following = false
 }
 
+// moveTo makes the event current and scrolls the log view so that the event is near the top of the page
+func (lv *LogView) moveTo(event *logEventLine) {
+	lv.top = event
+	lv.current = event
+	lv.adjustTop()
+	lv.top = lv.atOffset(lv.top, -lv.pageHeight/4) // scroll a little bit back
+}
+
 func (lv *LogView) adjustTop() {
 	if lv.distance(lv.current, lv.top) >= lv.pageHeight || !lv.highlightCurrent {
 		lv.top = lv.atOffset(lv.top, 1)
@@ -1355,9 +1696,9 @@ func (lv *LogView) scrollPageUp() {
 func (lv *LogView) scrollPageDown() {
 	lv.top = lv.atOffset(lv.top, lv.pageHeight)
 	lv.current = lv.atOffset(lv.current, lv.pageHeight)
-	if lv.current == lv.lastEvent {
+	if lv.nextVisible(lv.current) == nil && !lv.pageIn(false) {
 		lv.following = true
-		lv.top = lv.atOffset(lv.lastEvent, -(lv.pageHeight - 1))
+		lv.top = lv.atOffset(lv.current, -(lv.pageHeight - 1))
 	} else {
 		lv.following = false
 	}
@@ -1369,16 +1710,16 @@ func (lv *LogView) distance(start *logEventLine, target *logEventLine) int {
 	limit := lv.pageHeight
 	distance := 0
 	event := start
-	for limit > 0 {
+	for limit > 0 && event != nil {
 		if event != target {
-			event = event.previous
+			event = lv.prevVisible(event)
 		} else {
 			return distance
 		}
 		distance++
 		limit--
 	}
-	return distance
+	return lv.pageHeight
 }
 
 func (lv *LogView) getBackgroundColor() tcell.Color {
@@ -1391,41 +1732,11 @@ func (lv *LogView) getTextColor() tcell.Color {
 	return fg
 }
 
-// headerWidth returns the width of the header of the log line
-// If showSource or showTimestamp are enabled they create an additional header for the event
-func (lv *LogView) headerWidth() int {
-	w := 0
-	if lv.showSource {
-		w += lv.sourceHeaderWidth()
-	}
-	if lv.showTimestamp {
-		w += lv.timestampHeaderWidth()
-	}
-	return w
-}
-
-// isHeaderPossible determines whether page has enough room to display a header
-// if header is wider than half of the page then header would not be displayed
-func (lv *LogView) isHeaderPossible() bool {
-	return lv.headerWidth() < lv.fullPageWidth*1/2
-}
-
-func (lv *LogView) sourceHeaderWidth() int {
-	return lv.sourceClipLength + 3
-}
-
-func (lv *LogView) timestampHeaderWidth() int {
-	return len(lv.timestampFormat) + 3
-}
-
 func (lv *LogView) findByEventId(eventID string) *logEventLine {
-	event := lv.firstEvent
-	if eventID != "" {
-		for event != nil && event.EventID != eventID {
-			event = event.next
-		}
+	if eventID == "" {
+		return lv.firstEvent
 	}
-	return event
+	return lv.index.byID(eventID)
 }
 
 func (lv *LogView) isLastLine(event *logEventLine) bool {
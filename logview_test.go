@@ -359,14 +359,49 @@ func BenchmarkLogView(b *testing.B) {
 	ts := time.Now().Add(-24 * time.Hour)
 	events := randomBenchEvents(eventCount, ts)
 
+	b.Run("Append", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			lv.AppendEvents(events)
+			lv.Draw(screen)
+		}
+	})
+}
+
+// BenchmarkLogView_Navigation measures jumps in a large buffer, the buffer is filled once before the measurement
+func BenchmarkLogView_Navigation(b *testing.B) {
+	screen := tcell.NewSimulationScreen("UTF-8")
+	lv := NewLogView()
+	lv.SetRect(0, 0, 80, 25)
+	lv.Draw(screen)
+	ts := time.Now().Add(-24 * time.Hour)
+	lv.AppendEvents(randomBenchEvents(benchLargeEventCount, ts))
 	b.ResetTimer()
-	for n := 0; n < b.N; n++ {
-		lv.AppendEvents(events)
-		lv.Draw(screen)
-	}
 
+	b.Run("ScrollToEventID", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			lv.ScrollToEventID("e" + strconv.Itoa(n*7919%benchLargeEventCount))
+		}
+	})
+	b.Run("ScrollToTimestamp", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			lv.ScrollToTimestamp(ts.Add(time.Duration(n*7919%benchLargeEventCount) * time.Second))
+		}
+	})
+	b.Run("ScrollToPercent", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			lv.ScrollToPercent(float64(n % 101))
+		}
+	})
+	b.Run("FindMatchingEvent", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			id := "e" + strconv.Itoa(benchLargeEventCount-1-n%100)
+			lv.FindMatchingEvent(id, func(event *LogEvent) bool { return event.EventID == id })
+		}
+	})
 }
 
+const benchLargeEventCount = 1_000_000
+
 func randomBenchEvents(count int, startingTimestamp time.Time) []*LogEvent {
 	result := make([]*LogEvent, count)
 	for i := 0; i < count; i++ {
@@ -388,3 +423,38 @@ func randomEvents(count int, startingTimestamp time.Time) []*LogEvent {
 	}
 	return result
 }
+
+func TestLogView_TabExpansion(t *testing.T) {
+	lv := NewLogView()
+	lv.SetHighlightPattern(`(?P<number>\d+)`)
+	lv.AppendEvent(&LogEvent{EventID: "1", Message: "id\t42"})
+
+	e := lv.firstEvent
+	if string(e.Runes) != "id  42" {
+		t.Errorf("Tabs should be expanded to the next tab stop: %q", string(e.Runes))
+	}
+	if msg := lv.GetCurrentEvent().Message; msg != "id\t42" {
+		t.Errorf("Original message should be returned: %q", msg)
+	}
+	highlighted := false
+	for _, span := range e.styleSpans {
+		if string(e.Runes[span.start:span.end]) == "42" {
+			highlighted = true
+		}
+	}
+	if !highlighted {
+		t.Errorf("Highlighting should match the expanded text: %+v", e.styleSpans)
+	}
+
+	lv.SetTabWidth(8)
+	e = lv.firstEvent
+	if string(e.Runes) != "id      42" || lv.GetCurrentEvent().Message != "id\t42" {
+		t.Errorf("Changing tab width should update existing events: %q", string(e.Runes))
+	}
+	for _, span := range e.styleSpans {
+		if span.start < len(e.Runes) && string(e.Runes[span.start:minInt(span.end, len(e.Runes))]) == "42" {
+			return
+		}
+	}
+	t.Errorf("Highlighting should be updated after tab width change: %+v", e.styleSpans)
+}
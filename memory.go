@@ -0,0 +1,110 @@
+package logview
+
+import (
+	"time"
+	"unsafe"
+)
+
+const (
+	eventLineSize  = uint64(unsafe.Sizeof(logEventLine{}))
+	runeSize       = uint64(unsafe.Sizeof(rune(0)))
+	styledSpanSize = uint64(unsafe.Sizeof(styledSpan{}))
+	ansiSpanSize   = uint64(unsafe.Sizeof(ansiSpan{}))
+	textRangeSize  = uint64(unsafe.Sizeof(textRange{}))
+	// approximate overhead of a map entry with string key and value
+	fieldSize = uint64(2*unsafe.Sizeof("") + 16)
+)
+
+// SetMaxMemory sets the approximate number of bytes log events may take in memory. Size of an event includes its
+// message, highlighting and all the lines it is wrapped into, so a long stack trace takes as much as hundreds of short
+// events. When the budget is exceeded, the oldest events are evicted, the newest event is always kept.
+//
+// Memory budget is applied together with the event limit (see SetEventLimit) and the event age limit
+// (see SetMaxEventAge). To disable the budget set it to zero
+func (lv *LogView) SetMaxMemory(bytes uint64) {
+	defer lv.fireOnSelectionChange(lv.getSelection())
+	lv.Lock()
+	defer lv.Unlock()
+
+	lv.maxMemory = bytes
+	lv.ensureEventLimit()
+}
+
+// GetMaxMemory returns the memory budget of the log view in bytes, zero if there is no budget
+func (lv *LogView) GetMaxMemory() uint64 {
+	lv.RLock()
+	defer lv.RUnlock()
+
+	return lv.maxMemory
+}
+
+// GetMemoryUsage returns the approximate number of bytes taken by the events in the log view
+func (lv *LogView) GetMemoryUsage() uint64 {
+	lv.RLock()
+	defer lv.RUnlock()
+
+	return lv.memoryUsage
+}
+
+// SetMaxEventAge sets the maximum age of the events in the log view. Events with timestamps older than that are
+// evicted when new events are appended and when the log view is drawn, starting with the oldest one. Events without
+// timestamp are not evicted by age, the newest event is always kept. To disable the age limit set it to zero
+func (lv *LogView) SetMaxEventAge(age time.Duration) {
+	defer lv.fireOnSelectionChange(lv.getSelection())
+	lv.Lock()
+	defer lv.Unlock()
+
+	lv.maxEventAge = age
+	lv.ensureEventLimit()
+}
+
+// GetMaxEventAge returns the maximum age of the events in the log view, zero if there is no age limit
+func (lv *LogView) GetMaxEventAge() time.Duration {
+	lv.RLock()
+	defer lv.RUnlock()
+
+	return lv.maxEventAge
+}
+
+// *******************************
+// internal implementation details
+
+// exceedsLimits returns true if the oldest event has to be evicted to satisfy the event limit, memory budget and
+// optionally the age limit
+func (lv *LogView) exceedsLimits(checkAge bool) bool {
+	if lv.eventLimit > 0 && lv.eventCount > lv.eventLimit {
+		return true
+	}
+	if lv.eventCount <= 1 {
+		return false
+	}
+	if lv.maxMemory > 0 && lv.memoryUsage > lv.maxMemory {
+		return true
+	}
+	return checkAge && lv.maxEventAge > 0 && !lv.firstEvent.Timestamp.IsZero() &&
+		time.Since(lv.firstEvent.Timestamp) > lv.maxEventAge
+}
+
+// updateMemoryUsage recalculates the size of the event after it was changed
+func (lv *LogView) updateMemoryUsage(event *logEventLine) {
+	event = findFirstWrappedLine(event)
+	size := eventSize(event)
+	lv.memoryUsage = lv.memoryUsage - event.memorySize + size
+	event.memorySize = size
+}
+
+// eventSize returns the approximate number of bytes taken by the event and its wrapped lines
+func eventSize(event *logEventLine) uint64 {
+	size := uint64(cap(event.Runes))*runeSize + uint64(len(event.ansiSpans))*ansiSpanSize +
+		uint64(len(event.searchMatches))*textRangeSize + uint64(len(event.original))
+	for name, value := range event.Fields {
+		size += uint64(len(name)+len(value)) + fieldSize
+	}
+	for line := event; line != nil; line = line.next {
+		size += eventLineSize + uint64(len(line.styleSpans))*styledSpanSize
+		if line.next == nil || line.next.order <= 1 {
+			break
+		}
+	}
+	return size
+}
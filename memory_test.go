@@ -0,0 +1,145 @@
+package logview
+
+import (
+	"fmt"
+	"github.com/gdamore/tcell/v2"
+	"strings"
+	"testing"
+	"time"
+)
+
+// totalEventSize calculates the size of all the events from scratch
+func totalEventSize(lv *LogView) uint64 {
+	var size uint64
+	for e := lv.firstEvent; e != nil; e = findLastWrappedLine(e).next {
+		size += eventSize(e)
+	}
+	return size
+}
+
+func TestLogView_MaxMemory(t *testing.T) {
+	lv := NewLogView()
+	lv.pageWidth = 20
+	for i := 0; i < 10; i++ {
+		lv.AppendEvent(NewLogEvent(fmt.Sprint(i), "short event"))
+	}
+	usage := lv.GetMemoryUsage()
+	if usage == 0 || usage != totalEventSize(lv) {
+		t.Fatalf("Invalid memory usage %d, expected %d", usage, totalEventSize(lv))
+	}
+
+	lv.SetMaxMemory(usage)
+	lv.AppendEvent(NewLogEvent("trace", strings.Repeat("at com.example.Class.method(Class.java:42)\n", 3)))
+	if lv.GetMemoryUsage() > usage || lv.lastEvent.EventID != "trace" {
+		t.Errorf("Memory budget exceeded: %d of %d", lv.GetMemoryUsage(), usage)
+	}
+	if lv.GetEventCount() >= 10 {
+		t.Errorf("Long event must evict several short events, %d events left", lv.GetEventCount())
+	}
+
+	// the newest event is kept even if it doesn't fit
+	lv.SetMaxMemory(1)
+	if lv.GetEventCount() != 1 || lv.firstEvent.EventID != "trace" {
+		t.Errorf("The newest event must be kept")
+	}
+	if lv.GetMemoryUsage() != totalEventSize(lv) {
+		t.Errorf("Invalid memory usage %d, expected %d", lv.GetMemoryUsage(), totalEventSize(lv))
+	}
+}
+
+func TestLogView_MemoryUsageAfterRewrap(t *testing.T) {
+	lv := NewLogView()
+	lv.pageWidth = 40
+	appendLongEvents(lv, 20)
+	before := lv.GetMemoryUsage()
+
+	lv.Lock()
+	lv.pageWidth = 10
+	lv.invalidateWrap()
+	lv.Unlock()
+	if lv.GetMemoryUsage() <= before || lv.GetMemoryUsage() != totalEventSize(lv) {
+		t.Errorf("Wrapped lines must be counted: %d before, %d after, expected %d", before, lv.GetMemoryUsage(), totalEventSize(lv))
+	}
+
+	lv.Clear()
+	if lv.GetMemoryUsage() != 0 {
+		t.Errorf("Memory usage must be reset")
+	}
+}
+
+func TestLogView_MaxEventAge(t *testing.T) {
+	lv := NewLogView()
+	now := time.Now()
+	for i, age := range []time.Duration{3 * time.Hour, 2 * time.Hour, 30 * time.Minute, 0} {
+		event := NewLogEvent(fmt.Sprint(i), "event")
+		event.Timestamp = now.Add(-age)
+		lv.AppendEvent(event)
+	}
+
+	lv.SetMaxEventAge(time.Hour)
+	if lv.GetEventCount() != 2 || lv.firstEvent.EventID != "2" {
+		t.Errorf("Events older than an hour must be evicted, %d left", lv.GetEventCount())
+	}
+
+	// age and count limits are combined
+	lv.SetEventLimit(1)
+	if lv.GetEventCount() != 1 || lv.firstEvent.EventID != "3" {
+		t.Errorf("Event limit must still be applied")
+	}
+
+	old := NewLogEvent("old", "event")
+	old.Timestamp = now.Add(-2 * time.Hour)
+	lv.Clear()
+	lv.AppendEvent(old)
+	if lv.GetEventCount() != 1 || lv.firstEvent.EventID != "old" {
+		t.Errorf("The newest event must be kept regardless of its age")
+	}
+}
+
+func TestLogView_MaxEventAgeOnDraw(t *testing.T) {
+	screen := tcell.NewSimulationScreen("UTF-8")
+	screen.Init()
+	screen.SetSize(40, 5)
+	lv := NewLogView()
+	lv.SetRect(0, 0, 40, 5)
+	now := time.Now()
+	for i, age := range []time.Duration{time.Hour - 50*time.Millisecond, 0} {
+		event := NewLogEvent(fmt.Sprint(i), "event")
+		event.Timestamp = now.Add(-age)
+		lv.AppendEvent(event)
+	}
+	lv.SetMaxEventAge(time.Hour)
+	if lv.GetEventCount() != 2 {
+		t.Fatalf("Events younger than an hour must be kept, %d left", lv.GetEventCount())
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	lv.Draw(screen)
+	if lv.GetEventCount() != 1 || lv.firstEvent.EventID != "1" {
+		t.Errorf("Event that became too old must be evicted when drawn, %d left", lv.GetEventCount())
+	}
+}
+
+func TestLogView_SpillMaxMemory(t *testing.T) {
+	lv := newSpillingLogView(t, 0, 0)
+	lv.AppendEvent(NewLogEvent("0", "event 0"))
+	lv.SetMaxMemory(lv.GetMemoryUsage() * 10)
+	for i := 1; i < 100; i++ {
+		lv.AppendEvent(NewLogEvent(fmt.Sprint(i), fmt.Sprintf("event %d", i)))
+	}
+	budget := lv.GetMaxMemory()
+	if lv.GetSpilledEventCount() == 0 || lv.GetMemoryUsage() > budget {
+		t.Fatalf("Events must be spilled to keep the memory budget")
+	}
+
+	lv.ScrollToTop()
+	checkSpillWindow(t, lv)
+	if lv.current.EventID != "0" || lv.GetMemoryUsage() > budget {
+		t.Errorf("First event must be paged in within the budget, current %s, usage %d", lv.current.EventID, lv.GetMemoryUsage())
+	}
+	lv.ScrollToBottom()
+	checkSpillWindow(t, lv)
+	if lv.current.EventID != "99" || lv.GetMemoryUsage() > budget {
+		t.Errorf("Last event must be paged in within the budget, current %s, usage %d", lv.current.EventID, lv.GetMemoryUsage())
+	}
+}
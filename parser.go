@@ -0,0 +1,393 @@
+package logview
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/araddon/dateparse"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Parser converts raw text lines into log events.
+//
+// Parse always returns an event, even if the line cannot be parsed. In that case the whole line becomes the event
+// message and the error describes the problem, so the line is not lost.
+type Parser interface {
+	Parse(line string) (*LogEvent, error)
+}
+
+// ErrUnrecognizedLine is returned by parsers when the line doesn't have the expected format
+var ErrUnrecognizedLine = errors.New("unrecognized line format")
+
+// FieldNames defines names of the fields of structured log lines that are used to fill LogEvent fields.
+// First field found in the line is used.
+type FieldNames struct {
+	EventID   []string
+	Source    []string
+	Timestamp []string
+	Level     []string
+	Message   []string
+}
+
+// DefaultFieldNames are the field names used by JSONParser and LogfmtParser by default
+var DefaultFieldNames = FieldNames{
+	EventID:   []string{"id", "event_id", "eventId"},
+	Source:    []string{"source", "logger", "component", "caller"},
+	Timestamp: []string{"ts", "time", "timestamp", "@timestamp", "t"},
+	Level:     []string{"level", "lvl", "severity", "@level"},
+	Message:   []string{"msg", "message", "@message"},
+}
+
+// parserBase contains functionality shared by all the parsers
+type parserBase struct {
+	location *time.Location
+	lastID   uint64
+}
+
+// SetLocation sets the time zone for the timestamps that do not specify one. Default is the local time zone
+func (p *parserBase) SetLocation(location *time.Location) {
+	p.location = location
+}
+
+// newEvent creates an event for the line with a generated event id and current time as a timestamp
+func (p *parserBase) newEvent(line string) *LogEvent {
+	return &LogEvent{
+		EventID:   strconv.FormatUint(atomic.AddUint64(&p.lastID, 1), 10),
+		Timestamp: time.Now(),
+		Level:     LogLevelInfo,
+		Message:   line,
+	}
+}
+
+func (p *parserBase) parseTimestamp(ts string) (time.Time, error) {
+	location := p.location
+	if location == nil {
+		location = time.Local
+	}
+	return dateparse.ParseIn(ts, location)
+}
+
+// setFields fills event fields from the values found in the line. Only non-empty values are used
+func (p *parserBase) setFields(event *LogEvent, id, source, ts, level string) error {
+	var result error
+	if id != "" {
+		event.EventID = id
+	}
+	event.Source = source
+	if level != "" {
+		event.Level = parseLevel(level)
+	}
+	if ts != "" {
+		timestamp, err := p.parseTimestamp(ts)
+		if err != nil {
+			result = fmt.Errorf("invalid timestamp %q: %w", ts, err)
+		} else {
+			event.Timestamp = timestamp
+		}
+	}
+	return result
+}
+
+// parseLevel converts textual log level into LogLevel. Unlike ParseLogLevel it never fails: numeric levels are
+// treated as pino/bunyan levels (10 - trace, 20 - debug, ..., 60 - fatal) or syslog severities (0-7), unknown
+// spellings are recognized by their first letters, i.e. "DBG2" is debug. Anything else is info
+func parseLevel(level string) LogLevel {
+	if l, err := ParseLogLevel(level); err == nil {
+		return l
+	}
+	level = strings.ToLower(strings.TrimSpace(level))
+	if n, err := strconv.Atoi(level); err == nil {
+		switch {
+		case n < 0:
+			return LogLevelInfo
+		case n < len(syslogLevels):
+			return syslogLevels[n]
+		case n >= 60:
+			return LogLevelFatal
+		case n >= 50:
+			return LogLevelError
+		case n >= 40:
+			return LogLevelWarning
+		case n >= 30:
+			return LogLevelInfo
+		case n >= 20:
+			return LogLevelDebug
+		default:
+			return LogLevelTrace
+		}
+	}
+	switch {
+	case strings.HasPrefix(level, "fatal"), strings.HasPrefix(level, "panic"), strings.HasPrefix(level, "emerg"):
+		return LogLevelFatal
+	case strings.HasPrefix(level, "crit"), strings.HasPrefix(level, "alert"):
+		return LogLevelCritical
+	case strings.HasPrefix(level, "e"):
+		return LogLevelError
+	case strings.HasPrefix(level, "w"):
+		return LogLevelWarning
+	case strings.HasPrefix(level, "n"):
+		return LogLevelNotice
+	case strings.HasPrefix(level, "d"):
+		return LogLevelDebug
+	case strings.HasPrefix(level, "t"), strings.HasPrefix(level, "v"):
+		return LogLevelTrace
+	default:
+		return LogLevelInfo
+	}
+}
+
+// RegexParser parses lines with a regular expression.
+//
+// Named capturing groups define which parts of the line are used for the event fields:
+//
+// - ts - event timestamp
+//
+// - level - event level
+//
+// - source - event source
+//
+// - id - event id
+//
+// - msg - event message, if there is no such group, the whole line is used as a message
+//
+// All other named groups become event fields
+type RegexParser struct {
+	parserBase
+	pattern *regexp.Regexp
+}
+
+// NewRegexParser creates a parser for a given regular expression
+func NewRegexParser(pattern string) (*RegexParser, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return &RegexParser{pattern: re}, nil
+}
+
+// Parse converts a line of text into an event
+func (p *RegexParser) Parse(line string) (*LogEvent, error) {
+	event := p.newEvent(line)
+	match := p.pattern.FindStringSubmatch(line)
+	if match == nil {
+		return event, ErrUnrecognizedLine
+	}
+	groups := make(map[string]string)
+	for i, name := range p.pattern.SubexpNames() {
+		if name != "" && match[i] != "" {
+			groups[name] = match[i]
+			switch name {
+			case "ts", "level", "source", "id", "msg":
+			default:
+				if event.Fields == nil {
+					event.Fields = make(map[string]string)
+				}
+				event.Fields[name] = match[i]
+			}
+		}
+	}
+	if msg, ok := groups["msg"]; ok {
+		event.Message = msg
+	}
+	return event, p.setFields(event, groups["id"], groups["source"], groups["ts"], groups["level"])
+}
+
+// JSONParser parses lines containing JSON objects, one object per line.
+//
+// Object fields are mapped to event fields according to FieldNames. If there is no message field, the whole line is
+// used as a message. All other object fields become event Fields.
+type JSONParser struct {
+	parserBase
+	names FieldNames
+}
+
+// NewJSONParser creates a JSON lines parser with DefaultFieldNames
+func NewJSONParser() *JSONParser {
+	return &JSONParser{names: DefaultFieldNames}
+}
+
+// SetFieldNames sets the names of JSON fields that are mapped to the event fields
+func (p *JSONParser) SetFieldNames(names FieldNames) {
+	p.names = names
+}
+
+// Parse converts a line of text into an event
+func (p *JSONParser) Parse(line string) (*LogEvent, error) {
+	event := p.newEvent(line)
+	var object map[string]interface{}
+	if err := json.Unmarshal([]byte(line), &object); err != nil {
+		return event, fmt.Errorf("%w: %v", ErrUnrecognizedLine, err)
+	}
+	values := make(map[string]string, len(object))
+	for k, v := range object {
+		values[k] = jsonValueToString(v)
+	}
+	if msg := lookupField(values, p.names.Message); msg != "" {
+		event.Message = msg
+	}
+	ts := lookupField(values, p.names.Timestamp)
+	if t, ok := parseEpoch(ts); ok {
+		event.Timestamp = t
+		ts = ""
+	}
+	event.Fields = remainingFields(values, p.names)
+	return event, p.setFields(event, lookupField(values, p.names.EventID), lookupField(values, p.names.Source), ts,
+		lookupField(values, p.names.Level))
+}
+
+func jsonValueToString(v interface{}) string {
+	switch value := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(value)
+	default:
+		b, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Sprint(value)
+		}
+		return string(b)
+	}
+}
+
+// parseEpoch converts numeric timestamp into time. Values larger than 1e12 are treated as milliseconds, otherwise
+// as seconds
+func parseEpoch(ts string) (time.Time, bool) {
+	value, err := strconv.ParseFloat(ts, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	if value > 1e12 {
+		value = value / 1000
+	}
+	seconds, fraction := math.Modf(value)
+	return time.Unix(int64(seconds), int64(fraction*1e9)), true
+}
+
+func lookupField(values map[string]string, names []string) string {
+	for _, name := range names {
+		if v, ok := values[name]; ok {
+			return v
+		}
+	}
+	return ""
+}
+
+// remainingFields returns the values that are not mapped to any of the event fields
+func remainingFields(values map[string]string, names FieldNames) map[string]string {
+	used := make(map[string]bool)
+	for _, candidates := range [][]string{names.EventID, names.Source, names.Timestamp, names.Level, names.Message} {
+		for _, name := range candidates {
+			if _, ok := values[name]; ok {
+				used[name] = true
+				break
+			}
+		}
+	}
+	var fields map[string]string
+	for k, v := range values {
+		if !used[k] {
+			if fields == nil {
+				fields = make(map[string]string)
+			}
+			fields[k] = v
+		}
+	}
+	return fields
+}
+
+// LogfmtParser parses lines in logfmt format, i.e. sequence of key=value pairs separated by spaces. Values
+// containing spaces must be enclosed in double quotes.
+//
+// Keys are mapped to event fields according to FieldNames. If there is no message key, the whole line is
+// used as a message. All other keys become event Fields.
+type LogfmtParser struct {
+	parserBase
+	names FieldNames
+}
+
+// NewLogfmtParser creates logfmt parser with DefaultFieldNames
+func NewLogfmtParser() *LogfmtParser {
+	return &LogfmtParser{names: DefaultFieldNames}
+}
+
+// SetFieldNames sets the names of keys that are mapped to the event fields
+func (p *LogfmtParser) SetFieldNames(names FieldNames) {
+	p.names = names
+}
+
+// Parse converts a line of text into an event
+func (p *LogfmtParser) Parse(line string) (*LogEvent, error) {
+	event := p.newEvent(line)
+	values, err := parseLogfmt(line)
+	if err != nil {
+		return event, err
+	}
+	if msg := lookupField(values, p.names.Message); msg != "" {
+		event.Message = msg
+	}
+	event.Fields = remainingFields(values, p.names)
+	return event, p.setFields(event, lookupField(values, p.names.EventID), lookupField(values, p.names.Source),
+		lookupField(values, p.names.Timestamp), lookupField(values, p.names.Level))
+}
+
+// parseLogfmt splits the line into key/value pairs. Keys without values have empty values
+func parseLogfmt(line string) (map[string]string, error) {
+	values := make(map[string]string)
+	pos := 0
+	for pos < len(line) {
+		for pos < len(line) && line[pos] == ' ' {
+			pos++
+		}
+		if pos == len(line) {
+			break
+		}
+		start := pos
+		for pos < len(line) && line[pos] != '=' && line[pos] != ' ' {
+			pos++
+		}
+		key := line[start:pos]
+		if key == "" || line[start] == '"' {
+			return values, fmt.Errorf("%w: invalid key at position %d", ErrUnrecognizedLine, start)
+		}
+		if pos == len(line) || line[pos] == ' ' {
+			values[key] = ""
+			continue
+		}
+		pos++ // skip '='
+		if pos < len(line) && line[pos] == '"' {
+			end := pos + 1
+			for end < len(line) && line[end] != '"' {
+				if line[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(line) {
+				return values, fmt.Errorf("%w: unterminated quoted value for key %s", ErrUnrecognizedLine, key)
+			}
+			value, err := strconv.Unquote(line[pos : end+1])
+			if err != nil {
+				return values, fmt.Errorf("%w: invalid quoted value for key %s", ErrUnrecognizedLine, key)
+			}
+			values[key] = value
+			pos = end + 1
+		} else {
+			start = pos
+			for pos < len(line) && line[pos] != ' ' {
+				pos++
+			}
+			values[key] = line[start:pos]
+		}
+	}
+	return values, nil
+}
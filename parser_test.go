@@ -0,0 +1,144 @@
+package logview
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRegexParser_Parse(t *testing.T) {
+	parser, err := NewRegexParser(`^(?P<ts>\S+ \S+) \[(?P<source>[^]]+)] (?P<level>\w+) (?P<id>\d+) (?P<user>\w+): (?P<msg>.*)$`)
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+	parser.SetLocation(time.UTC)
+
+	event, err := parser.Parse("2021-03-06 21:16:34.198 [main] WARN 42 root: Disk is almost full")
+	if err != nil {
+		t.Fatalf("Failed to parse line: %v", err)
+	}
+	expectedTs := time.Date(2021, 3, 6, 21, 16, 34, 198_000_000, time.UTC)
+	if event.EventID != "42" || event.Source != "main" || event.Level != LogLevelWarning ||
+		event.Message != "Disk is almost full" || !event.Timestamp.Equal(expectedTs) ||
+		len(event.Fields) != 1 || event.Fields["user"] != "root" {
+		t.Errorf("Invalid event: %+v", event)
+	}
+
+	event, err = parser.Parse("not a log line")
+	if !errors.Is(err, ErrUnrecognizedLine) || event == nil || event.Message != "not a log line" {
+		t.Errorf("Unrecognized line must be returned as a message with error, event=%+v, err=%v", event, err)
+	}
+}
+
+func TestJSONParser_Parse(t *testing.T) {
+	parser := NewJSONParser()
+	event, err := parser.Parse(`{"ts":1614996994.5,"level":"error","logger":"db","msg":"connection lost","id":"e1"}`)
+	if err != nil {
+		t.Fatalf("Failed to parse line: %v", err)
+	}
+	if event.EventID != "e1" || event.Source != "db" || event.Level != LogLevelError ||
+		event.Message != "connection lost" || !event.Timestamp.Equal(time.Unix(1614996994, 500_000_000)) {
+		t.Errorf("Invalid event: %+v", event)
+	}
+
+	if event.Fields != nil {
+		t.Errorf("All JSON fields are mapped, event fields must be empty: %v", event.Fields)
+	}
+
+	event, err = parser.Parse(`{"time":"2021-03-06T21:16:34Z","message":"started","request_id":"r-1","duration":12}`)
+	if err != nil || event.Message != "started" || !event.Timestamp.Equal(time.Date(2021, 3, 6, 21, 16, 34, 0, time.UTC)) ||
+		event.Fields["request_id"] != "r-1" || event.Fields["duration"] != "12" || len(event.Fields) != 2 {
+		t.Errorf("Invalid event: %+v, err=%v", event, err)
+	}
+
+	event, err = parser.Parse(`{"msg": "broken`)
+	if err == nil || event.Message != `{"msg": "broken` {
+		t.Errorf("Invalid JSON must be returned as a message with error")
+	}
+
+	event, err = parser.Parse(`{"msg":"bad time","ts":"yesterday"}`)
+	if err == nil || event.Message != "bad time" {
+		t.Errorf("Invalid timestamp must be reported, event=%+v", event)
+	}
+}
+
+func TestLogfmtParser_Parse(t *testing.T) {
+	parser := NewLogfmtParser()
+	parser.SetLocation(time.UTC)
+	event, err := parser.Parse(`ts=2021-03-06T21:16:34Z level=warn msg="slow \"query\" detected" duration=12ms cached`)
+	if err != nil {
+		t.Fatalf("Failed to parse line: %v", err)
+	}
+	if event.Level != LogLevelWarning || event.Message != `slow "query" detected` ||
+		!event.Timestamp.Equal(time.Date(2021, 3, 6, 21, 16, 34, 0, time.UTC)) {
+		t.Errorf("Invalid event: %+v", event)
+	}
+	if len(event.Fields) != 2 || event.Fields["duration"] != "12ms" || event.Fields["cached"] != "" {
+		t.Errorf("Unmapped keys must become event fields: %v", event.Fields)
+	}
+
+	event, err = parser.Parse(`msg="unterminated`)
+	if !errors.Is(err, ErrUnrecognizedLine) || event.Message != `msg="unterminated` {
+		t.Errorf("Unterminated value must be reported, event=%+v, err=%v", event, err)
+	}
+}
+
+func TestSyslogParser_Parse(t *testing.T) {
+	parser := NewSyslogParser()
+	parser.SetLocation(time.UTC)
+
+	event, err := parser.Parse(`<34>Oct 11 22:14:15 mymachine su[123]: 'su root' failed for lonvick on /dev/pts/8`)
+	if err != nil {
+		t.Fatalf("Failed to parse RFC 3164 line: %v", err)
+	}
+	if event.Level != LogLevelCritical || event.Source != "su" || event.Message != "'su root' failed for lonvick on /dev/pts/8" ||
+		event.Timestamp.Month() != time.October || event.Timestamp.Day() != 11 || event.Timestamp.Hour() != 22 ||
+		event.Fields["host"] != "mymachine" || event.Fields["pid"] != "123" {
+		t.Errorf("Invalid event: %+v", event)
+	}
+
+	event, err = parser.Parse(`<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog - ID47 [exampleSDID@32473 iut="3" eventSource="App]"] An application event`)
+	if err != nil {
+		t.Fatalf("Failed to parse RFC 5424 line: %v", err)
+	}
+	if event.Level != LogLevelNotice || event.Source != "evntslog" || event.Message != "An application event" ||
+		!event.Timestamp.Equal(time.Date(2003, 10, 11, 22, 14, 15, 3_000_000, time.UTC)) ||
+		event.Fields["msgid"] != "ID47" || event.Fields["pid"] != "" {
+		t.Errorf("Invalid event: %+v", event)
+	}
+
+	// timestamps are parsed in the parser location when the zone is missing
+	event, err = parser.Parse(`<14>1 2003-10-11T22:14:15 host app - - - local time`)
+	if err != nil || event.Timestamp.Format(time.RFC3339) != "2003-10-11T22:14:15Z" {
+		t.Errorf("Timestamp must be parsed in the parser location: %v, err=%v", event.Timestamp, err)
+	}
+	event, err = parser.Parse(`<14>1 2003-10-11T22:14:15+02:00 host app - - - with offset`)
+	if err != nil || !event.Timestamp.Equal(time.Date(2003, 10, 11, 20, 14, 15, 0, time.UTC)) {
+		t.Errorf("Timestamp offset must be kept: %v, err=%v", event.Timestamp, err)
+	}
+
+	event, err = parser.Parse(`no priority`)
+	if !errors.Is(err, ErrUnrecognizedLine) || event.Message != "no priority" {
+		t.Errorf("Line without priority must be reported, event=%+v, err=%v", event, err)
+	}
+}
+
+func TestParser_LenientLevels(t *testing.T) {
+	parser := NewJSONParser()
+	for level, expected := range map[string]LogLevel{
+		`30`:       LogLevelInfo,
+		`40`:       LogLevelWarning,
+		`50`:       LogLevelError,
+		`60`:       LogLevelFatal,
+		`3`:        LogLevelError,
+		`"CONFIG"`: LogLevelInfo,
+		`"DBG2"`:   LogLevelDebug,
+		`"Warn1"`:  LogLevelWarning,
+		`"SEVERE"`: LogLevelError,
+	} {
+		event, err := parser.Parse(`{"level":` + level + `,"msg":"message"}`)
+		if err != nil || event.Level != expected {
+			t.Errorf("Level %s must be parsed as %v, got %v, err=%v", level, expected, event.Level, err)
+		}
+	}
+}
@@ -0,0 +1,267 @@
+package logview
+
+// refreshChunkSize is the number of events processed in background at once, the log view is locked while the chunk
+// is processed. Buffers of this size or smaller are refreshed immediately
+const refreshChunkSize = 500
+
+// OnRefreshProgress is called from the background goroutine while highlighting and wrapping of the events is being
+// recalculated. done is the number of events processed so far, when done is equal to total the refresh is finished
+type OnRefreshProgress func(done uint, total uint)
+
+// SetOnRefreshProgress sets the listener that is notified about the progress of the background recalculation of
+// highlighting and wrapping.
+//
+// When highlighting settings change (see RefreshHighlights) or the log view is resized, the events displayed on the
+// page are updated immediately and the rest of the events are processed in background in small chunks, so appending
+// and drawing are not blocked. If the settings change again before the refresh is finished, the running refresh is
+// cancelled and a new one starts. Listener is called after each chunk, it is not called for the small buffers that
+// are refreshed immediately or after the refresh is cancelled.
+func (lv *LogView) SetOnRefreshProgress(listener OnRefreshProgress) {
+	lv.Lock()
+	defer lv.Unlock()
+
+	lv.onRefreshProgress = listener
+}
+
+// IsRefreshInProgress returns true while highlighting and wrapping of the events is being recalculated in background
+func (lv *LogView) IsRefreshInProgress() bool {
+	lv.RLock()
+	defer lv.RUnlock()
+
+	return lv.refreshing
+}
+
+// SetLazyProcessing enables calculation of highlighting and wrapping of the events only when they are about to be
+// displayed. Events appended in lazy mode are not processed until they enter the page or come within lookahead lines
+// of it, and the results are cached afterwards. Events evicted by the event limit before anyone sees them are never
+// processed, which makes appends of high-volume streams much faster.
+//
+// Background refresh is not performed in lazy mode, events are updated when they are displayed. Disabling lazy mode
+// processes all the pending events. Default is disabled
+func (lv *LogView) SetLazyProcessing(enabled bool, lookahead int) {
+	lv.Lock()
+	defer lv.Unlock()
+
+	if lookahead < 0 {
+		lookahead = 0
+	}
+	lv.lazyLookahead = lookahead
+	if lv.lazy == enabled {
+		return
+	}
+	lv.lazy = enabled
+	if !enabled {
+		lv.refreshLines()
+	}
+}
+
+// IsLazyProcessingEnabled returns true if highlighting and wrapping are calculated only for the displayed events
+func (lv *LogView) IsLazyProcessingEnabled() bool {
+	lv.RLock()
+	defer lv.RUnlock()
+
+	return lv.lazy
+}
+
+// *******************************
+// internal implementation details
+
+// invalidateHighlights marks highlighting of all the events as outdated and starts the refresh
+func (lv *LogView) invalidateHighlights() {
+	lv.colorGeneration++
+	lv.refreshLines()
+}
+
+// invalidateWrap marks wrapping of all the events as outdated and starts the refresh
+func (lv *LogView) invalidateWrap() {
+	lv.wrapGeneration++
+	lv.refreshLines()
+}
+
+// refreshLines updates the events displayed on the page and then the rest of the events. Small buffers are processed
+// immediately, larger ones in background
+func (lv *LogView) refreshLines() {
+	lv.preparePage()
+	if lv.lazy {
+		lv.refreshing = false
+		return
+	}
+	if lv.eventCount <= refreshChunkSize {
+		for event := lv.firstEvent; event != nil; event = findLastWrappedLine(event).next {
+			event = lv.prepareEvent(event)
+		}
+		lv.refreshing = false
+		return
+	}
+	lv.refreshing = true
+	go lv.refreshInBackground(lv.colorGeneration, lv.wrapGeneration)
+}
+
+// refreshInBackground processes events in chunks until all of them are up to date or the generation changes
+func (lv *LogView) refreshInBackground(colorGeneration uint, wrapGeneration uint) {
+	var next *logEventLine
+	var done uint
+	started := false
+	for {
+		lv.Lock()
+		if lv.colorGeneration != colorGeneration || lv.wrapGeneration != wrapGeneration {
+			lv.Unlock()
+			return // cancelled by a newer refresh
+		}
+		if !started || (next != nil && !lv.isLinked(next)) {
+			// the event was removed or replaced since the last chunk, start over, processed events are skipped quickly
+			next = lv.firstEvent
+			done = 0
+			started = true
+		}
+		for i := 0; next != nil && i < refreshChunkSize; i++ {
+			next = findLastWrappedLine(lv.prepareEvent(next)).next
+			done++
+		}
+		total := lv.eventCount
+		if next == nil {
+			done = total
+			lv.refreshing = false
+		}
+		listener := lv.onRefreshProgress
+		lv.Unlock()
+
+		if listener != nil && !lv.notifyRefreshProgress(listener, colorGeneration, wrapGeneration, minUint(done, total), total) {
+			return
+		}
+		if next == nil {
+			return
+		}
+	}
+}
+
+// notifyRefreshProgress calls the listener unless the refresh was cancelled. Returns false if it was cancelled.
+// Listener calls are serialized, so the progress of the cancelled refresh is never reported after the progress of
+// the refresh that replaced it
+func (lv *LogView) notifyRefreshProgress(listener OnRefreshProgress, colorGeneration uint, wrapGeneration uint, done uint, total uint) bool {
+	lv.progressLock.Lock()
+	defer lv.progressLock.Unlock()
+
+	lv.RLock()
+	current := lv.colorGeneration == colorGeneration && lv.wrapGeneration == wrapGeneration
+	lv.RUnlock()
+	if current {
+		listener(done, total)
+	}
+	return current
+}
+
+// isPrepared returns true if highlighting and wrapping of the event line are up to date
+func (lv *LogView) isPrepared(event *logEventLine) bool {
+	return event.colorGeneration == lv.colorGeneration && event.wrapGeneration == lv.wrapGeneration
+}
+
+// isLinked returns true if the event line is still in the list
+func (lv *LogView) isLinked(event *logEventLine) bool {
+	if event.previous == nil {
+		return event == lv.firstEvent
+	}
+	return event.previous.next == event
+}
+
+// prepareEvent brings highlighting and wrapping of the event up to date. Top and current lines that belong to the
+// event keep pointing to the same part of the message. Returns the first line of the event
+func (lv *LogView) prepareEvent(event *logEventLine) *logEventLine {
+	event = findFirstWrappedLine(event)
+	if lv.isPrepared(event) {
+		return event
+	}
+	topPos, currentPos := positionInEvent(event, lv.top), positionInEvent(event, lv.current)
+
+	event = lv.mergeWrappedLines(event)
+	if event.colorGeneration != lv.colorGeneration {
+		lv.colorize(event)
+	}
+	event = findFirstWrappedLine(lv.calculateWrap(event))
+	lv.updateMemoryUsage(event)
+
+	if topPos >= 0 {
+		lv.top = lineAtPosition(event, topPos)
+	}
+	if currentPos >= 0 {
+		lv.current = lineAtPosition(event, currentPos)
+	}
+	return event
+}
+
+// prepareLine brings the event of the line up to date and returns the line displaying the same part of the message
+func (lv *LogView) prepareLine(line *logEventLine) *logEventLine {
+	if line == nil || lv.isPrepared(line) {
+		return line
+	}
+	pos := line.start
+	return lineAtPosition(lv.prepareEvent(line), pos)
+}
+
+// prevPrepared returns the last line of the previous visible event, preparing the event if needed
+func (lv *LogView) prevPrepared(line *logEventLine) *logEventLine {
+	prev := lv.prevVisible(line)
+	if prev == nil || lv.isPrepared(prev) {
+		return prev
+	}
+	return findLastWrappedLine(lv.prepareEvent(prev))
+}
+
+// linkedOrLast returns the event if it is still in the list, otherwise the last event
+func (lv *LogView) linkedOrLast(event *logEventLine) *logEventLine {
+	if event == nil || !lv.isLinked(event) {
+		return lv.lastEvent
+	}
+	return event
+}
+
+// preparePage brings up to date the events displayed on the page and the lookahead lines. In following mode events
+// are prepared from the last one, so the page still ends with the last event if wrapping changes
+func (lv *LogView) preparePage() {
+	pageLines := lv.pageHeight
+	if lv.lazy {
+		pageLines += lv.lazyLookahead
+	}
+	if lv.following {
+		changed := false
+		lines := 0
+		for event := lv.lastVisible(); event != nil && lines < pageLines; event = lv.prevVisible(event) {
+			changed = changed || !lv.isPrepared(event)
+			event = lv.prepareEvent(event)
+			lines += int(event.lineCount)
+		}
+		if changed {
+			lv.top = lv.atOffset(lv.lastVisible(), -(lv.pageHeight - 1))
+		}
+		return
+	}
+	lines := 0
+	for line := lv.top; line != nil && lines < pageLines; line = lv.nextVisible(line) {
+		line = lv.prepareLine(line)
+		lines++
+	}
+}
+
+// positionInEvent returns the start of the line in the event message if the line belongs to the event, -1 otherwise
+func positionInEvent(event *logEventLine, line *logEventLine) int {
+	if line == nil {
+		return -1
+	}
+	for e := event; e != nil; e = e.next {
+		if e == line {
+			return e.start
+		}
+		if e.next == nil || e.next.order <= 1 {
+			break
+		}
+	}
+	return -1
+}
+
+// lineAtPosition returns the line of the event that displays the given position of the message
+func lineAtPosition(event *logEventLine, pos int) *logEventLine {
+	for event.next != nil && event.next.order > 1 && event.next.start <= pos {
+		event = event.next
+	}
+	return event
+}
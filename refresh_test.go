@@ -0,0 +1,197 @@
+package logview
+
+import (
+	"fmt"
+	"github.com/gdamore/tcell/v2"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// waitForRefresh sets progress listener and returns the channel that is closed when the refresh is finished
+func waitForRefresh(lv *LogView) <-chan struct{} {
+	finished := make(chan struct{})
+	var once sync.Once
+	lv.SetOnRefreshProgress(func(done uint, total uint) {
+		if done == total {
+			once.Do(func() { close(finished) })
+		}
+	})
+	return finished
+}
+
+func appendLongEvents(lv *LogView, count int) {
+	events := make([]*LogEvent, count)
+	for i := range events {
+		events[i] = NewLogEvent(fmt.Sprint(i), fmt.Sprintf("%04d %s", i, strings.Repeat("abcdefghij", 5)))
+	}
+	lv.AppendEvents(events)
+}
+
+func TestLogView_BackgroundRewrap(t *testing.T) {
+	screen := tcell.NewSimulationScreen("UTF-8")
+	screen.Init()
+	screen.SetSize(40, 5)
+	lv := NewLogView()
+	lv.SetRect(0, 0, 40, 5)
+	lv.Draw(screen)
+	appendLongEvents(lv, refreshChunkSize*4)
+
+	finished := waitForRefresh(lv)
+	screen.SetSize(30, 5)
+	lv.SetRect(0, 0, 30, 5)
+	lv.Draw(screen)
+	screen.Show()
+
+	// page is re-wrapped immediately and still ends with the last event
+	expected := []string{"1999 abcdefghijabcdefghijabcde", "fghijabcdefghijabcdefghij"}
+	for i, line := range expected {
+		if screenLine(screen, i+3) != line {
+			t.Errorf("Invalid line %d: '%s', expected '%s'", i+3, screenLine(screen, i+3), line)
+		}
+	}
+
+	select {
+	case <-finished:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Refresh is not finished")
+	}
+
+	lv.RLock()
+	defer lv.RUnlock()
+	if lv.refreshing {
+		t.Errorf("Refresh must not be in progress")
+	}
+	for e := lv.firstEvent; e != nil; e = e.next {
+		if !lv.isPrepared(e) || e.end-e.start > 30 {
+			t.Fatalf("Event %s is not re-wrapped: %d-%d", e.EventID, e.start, e.end)
+		}
+	}
+}
+
+func TestLogView_RefreshCancelled(t *testing.T) {
+	lv := NewLogView()
+	lv.pageWidth = 20
+	appendLongEvents(lv, refreshChunkSize*4)
+
+	finished := waitForRefresh(lv)
+	lv.RefreshHighlights()
+	lv.SetHighlightPattern(`(?P<red>abc)`)
+	lv.RefreshHighlights() // cancels the first refresh, only the second one reports completion
+
+	select {
+	case <-finished:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Refresh is not finished")
+	}
+
+	lv.RLock()
+	colorGeneration, wrapGeneration := lv.colorGeneration, lv.wrapGeneration
+	lv.RUnlock()
+	if lv.notifyRefreshProgress(func(uint, uint) {
+		t.Errorf("Progress of the cancelled refresh must not be reported")
+	}, colorGeneration-1, wrapGeneration, 1, 1) {
+		t.Errorf("Cancelled refresh must stop")
+	}
+
+	lv.RLock()
+	defer lv.RUnlock()
+	for e := lv.firstEvent; e != nil; e = findLastWrappedLine(e).next {
+		if e.colorGeneration != lv.colorGeneration || len(e.styleSpans) < 2 {
+			t.Fatalf("Event %s is not re-highlighted", e.EventID)
+		}
+	}
+}
+
+func TestLogView_RefreshWrappedEvents(t *testing.T) {
+	lv := NewLogView()
+	lv.pageWidth = 20
+	lv.AppendEvent(NewLogEvent("1", strings.Repeat("abc ", 20)))
+	lv.SetHighlightPattern(`(?P<red>abc)`)
+	lv.RefreshHighlights()
+
+	if lv.IsRefreshInProgress() {
+		t.Errorf("Small buffer must be refreshed immediately")
+	}
+	lines := wrappedLines(lv)
+	if len(lines) != 4 || lv.firstEvent.lineCount != 4 {
+		t.Fatalf("Event must stay wrapped: %q", lines)
+	}
+	for e := lv.firstEvent; e != nil; e = e.next {
+		if fg, _, _ := e.styleSpans[0].style.Decompose(); fg != tcell.ColorRed {
+			t.Errorf("Line %d is not re-highlighted", e.order)
+		}
+	}
+}
+
+func TestLogView_LazyProcessing(t *testing.T) {
+	screen := tcell.NewSimulationScreen("UTF-8")
+	screen.Init()
+	screen.SetSize(40, 5)
+	lv := NewLogView()
+	lv.SetRect(0, 0, 40, 5)
+	lv.SetEventLimit(100)
+	lv.SetLazyProcessing(true, 2)
+	lv.Draw(screen)
+	appendLongEvents(lv, 1000)
+
+	processed := 0
+	for e := lv.firstEvent; e != nil; e = findLastWrappedLine(e).next {
+		if e.colorGeneration != 0 {
+			processed++
+		}
+	}
+	// only the events that fit the page when the batch ends are processed to find the top line
+	if processed > 3 {
+		t.Errorf("Events must not be processed before they are displayed, %d processed", processed)
+	}
+
+	lv.Draw(screen)
+	screen.Show()
+	if line := screenLine(screen, 4); line != "fghijabcdefghij" {
+		t.Errorf("Invalid last line: '%s'", line)
+	}
+	processed = 0
+	for e := lv.firstEvent; e != nil; e = findLastWrappedLine(e).next {
+		if lv.isPrepared(e) {
+			processed++
+		}
+	}
+	// 5 lines of the page and 2 lines of lookahead take 4 events
+	if processed != 4 {
+		t.Errorf("Only the events on the page and lookahead must be processed, %d processed", processed)
+	}
+
+	lv.SetLazyProcessing(false, 0)
+	for e := lv.firstEvent; e != nil; e = e.next {
+		if !lv.isPrepared(e) {
+			t.Fatalf("All the events must be processed when lazy mode is disabled")
+		}
+	}
+}
+
+func TestLogView_LazyScrolling(t *testing.T) {
+	screen := tcell.NewSimulationScreen("UTF-8")
+	screen.Init()
+	screen.SetSize(40, 5)
+	lv := NewLogView()
+	lv.SetRect(0, 0, 40, 5)
+	lv.SetLazyProcessing(true, 0)
+	lv.Draw(screen)
+	for i := 0; i < 20; i++ {
+		lv.AppendEvent(NewLogEvent(fmt.Sprint(i), strings.Repeat("0123456789", 10)))
+	}
+	lv.ScrollToTop()
+	lv.ScrollPageDown()
+
+	// every event takes 3 lines, so the page starts with the last line of the second event
+	top := lv.top
+	if top.EventID != "1" || top.order != 3 || lv.current.EventID != "1" {
+		t.Errorf("Invalid top line after scrolling: event %s, line %d", top.EventID, top.order)
+	}
+	lv.ScrollPageDown()
+	if lv.top.EventID != "3" || lv.top.order != 2 {
+		t.Errorf("Invalid top line after scrolling: event %s, line %d", lv.top.EventID, lv.top.order)
+	}
+}
@@ -0,0 +1,101 @@
+package logview
+
+import "github.com/gdamore/tcell/v2"
+
+// horizontalScrollStep is the number of characters the view is scrolled by with keyboard or mouse wheel
+const horizontalScrollStep = 8
+
+// SetHorizontalOffset scrolls the event messages horizontally, so that the first offset screen cells of the message
+// are hidden. Header columns are not scrolled.
+//
+// Horizontal scrolling is only possible when line wrapping is disabled. Enabling line wrapping resets the offset
+func (lv *LogView) SetHorizontalOffset(offset int) {
+	lv.Lock()
+	defer lv.Unlock()
+
+	if lv.wrap || offset < 0 {
+		offset = 0
+	}
+	lv.horizontalOffset = offset
+}
+
+// GetHorizontalOffset returns the current horizontal scroll offset
+func (lv *LogView) GetHorizontalOffset() int {
+	lv.RLock()
+	defer lv.RUnlock()
+
+	return lv.horizontalOffset
+}
+
+// ScrollLeft scrolls the event messages to the left, if line wrapping is disabled
+func (lv *LogView) ScrollLeft() {
+	lv.Lock()
+	defer lv.Unlock()
+
+	lv.scrollHorizontally(-horizontalScrollStep)
+}
+
+// ScrollRight scrolls the event messages to the right, if line wrapping is disabled. View is not scrolled past
+// the end of the longest message displayed on the page
+func (lv *LogView) ScrollRight() {
+	lv.Lock()
+	defer lv.Unlock()
+
+	lv.scrollHorizontally(horizontalScrollStep)
+}
+
+// *******************************
+// internal implementation details
+
+func (lv *LogView) scrollHorizontally(delta int) {
+	if lv.wrap {
+		return
+	}
+	offset := lv.horizontalOffset + delta
+	if maxOffset := lv.maxHorizontalOffset(); offset > maxOffset {
+		offset = maxOffset
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	lv.horizontalOffset = offset
+}
+
+// maxHorizontalOffset returns the offset at which the end of the longest line on the page is visible
+func (lv *LogView) maxHorizontalOffset() int {
+	longest := 0
+	event := lv.top
+	for i := 0; event != nil && i < lv.pageHeight; i++ {
+		if length := runesWidth(event.Runes[event.start:event.end]); length > longest {
+			longest = length
+		}
+		event = lv.nextVisible(event)
+	}
+	if longest > lv.pageWidth {
+		return longest - lv.pageWidth
+	}
+	return 0
+}
+
+// lineStart returns the position of the first character of the line that is visible on screen
+func (lv *LogView) lineStart(event *logEventLine) int {
+	if lv.wrap {
+		return event.start
+	}
+	return skipCells(event.Runes, event.start, event.end, lv.horizontalOffset)
+}
+
+// drawScrollIndicators marks the line edges when the line text continues off-screen. textEnd is the position
+// after the last character printed
+func (lv *LogView) drawScrollIndicators(screen tcell.Screen, x int, y int, width int, event *logEventLine, textEnd int) {
+	if lv.wrap || width < 2 {
+		return
+	}
+	style := lv.defaultStyle.Reverse(true)
+	if lv.lineStart(event) > event.start {
+		screen.SetCell(x, y, style, '«')
+	}
+	if textEnd < event.end {
+		screen.SetCell(x+width-1, y, style, '»')
+	}
+}
@@ -0,0 +1,57 @@
+package logview
+
+import (
+	"github.com/gdamore/tcell/v2"
+	gui "github.com/rivo/tview"
+	"testing"
+	"time"
+)
+
+func TestLogView_HorizontalScroll(t *testing.T) {
+	screen := tcell.NewSimulationScreen("UTF-8")
+	screen.Init()
+	screen.SetSize(40, 5)
+	lv := NewLogView()
+	lv.SetRect(0, 0, 40, 5)
+	lv.SetLineWrap(false)
+	lv.SetShowSource(true)
+	lv.SetSourceClipLength(4)
+	lv.AppendEvent(&LogEvent{EventID: "1", Source: "main", Timestamp: time.Now(),
+		Message: "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJ"})
+	lv.AppendEvent(&LogEvent{EventID: "2", Source: "main", Timestamp: time.Now(), Message: "short"})
+	lv.Draw(screen)
+	screen.Show()
+
+	if line := screenLine(screen, 0); line != "main | 0123456789abcdefghijklmnopqrstuv»" {
+		t.Errorf("Line continuing off-screen should be marked: '%s'", line)
+	}
+
+	lv.InputHandler()(tcell.NewEventKey(tcell.KeyRight, 0, tcell.ModNone), nil)
+	if lv.GetHorizontalOffset() != 8 {
+		t.Errorf("Right key should scroll horizontally, offset=%d", lv.GetHorizontalOffset())
+	}
+	lv.Draw(screen)
+	screen.Show()
+	if line := screenLine(screen, 0); line != "main | «9abcdefghijklmnopqrstuvwxyzABCD»" {
+		t.Errorf("Scrolled line with header pinned expected: '%s'", line)
+	}
+	if line := screenLine(screen, 1); line != "main | «" {
+		t.Errorf("Short line should be scrolled out of view: '%s'", line)
+	}
+
+	lv.ScrollRight()
+	lv.ScrollRight()
+	if lv.GetHorizontalOffset() != 13 {
+		t.Errorf("Should not scroll past the end of the longest line, offset=%d", lv.GetHorizontalOffset())
+	}
+
+	lv.MouseHandler()(gui.MouseScrollUp, tcell.NewEventMouse(1, 1, tcell.WheelUp, tcell.ModShift), nil)
+	if lv.GetHorizontalOffset() != 5 {
+		t.Errorf("Shift+wheel should scroll horizontally, offset=%d", lv.GetHorizontalOffset())
+	}
+
+	lv.SetLineWrap(true)
+	if lv.GetHorizontalOffset() != 0 {
+		t.Errorf("Enabling line wrap should reset the offset, offset=%d", lv.GetHorizontalOffset())
+	}
+}
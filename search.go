@@ -0,0 +1,272 @@
+package logview
+
+import (
+	"github.com/dlclark/regexp2"
+	"github.com/gdamore/tcell/v2"
+)
+
+// textRange is a range of runes in the event message, end is exclusive
+type textRange struct {
+	start int
+	end   int
+}
+
+// SetSearchText sets the plain text to search for in the event messages. Search is case-insensitive.
+//
+// Every occurrence of the text is highlighted with the style set by SetSearchMatchStyle. Use SearchNext and SearchPrev
+// to navigate between matching events. Setting empty text clears the search.
+func (lv *LogView) SetSearchText(text string) {
+	lv.Lock()
+	defer lv.Unlock()
+
+	if text == "" {
+		lv.searchPattern = nil
+	} else {
+		lv.searchPattern = regexp2.MustCompile(regexp2.Escape(text), regexp2.IgnoreCase+regexp2.RE2)
+	}
+	lv.researchLines()
+}
+
+// SetSearchRegex sets the regular expression to search for in the event messages. Search is case-insensitive.
+//
+// Regular expression syntax is the same as for SetHighlightPattern. Returns error if the regular expression cannot be
+// compiled, the search is not changed in that case. Setting empty pattern clears the search.
+func (lv *LogView) SetSearchRegex(pattern string) error {
+	var searchPattern *regexp2.Regexp
+	if pattern != "" {
+		var err error
+		searchPattern, err = regexp2.Compile(pattern, regexp2.IgnoreCase+regexp2.RE2)
+		if err != nil {
+			return err
+		}
+	}
+
+	lv.Lock()
+	defer lv.Unlock()
+
+	lv.searchPattern = searchPattern
+	lv.researchLines()
+	return nil
+}
+
+// ClearSearch removes search and search match highlighting
+func (lv *LogView) ClearSearch() {
+	lv.SetSearchText("")
+}
+
+// IsSearchActive returns true if the search text or regular expression is set
+func (lv *LogView) IsSearchActive() bool {
+	lv.RLock()
+	defer lv.RUnlock()
+
+	return lv.searchPattern != nil
+}
+
+// SetSearchMatchStyle sets the style to highlight search matches
+func (lv *LogView) SetSearchMatchStyle(style tcell.Style) {
+	lv.Lock()
+	defer lv.Unlock()
+
+	lv.searchStyle = style
+}
+
+// SearchNext selects the next event after the current one that matches the search and scrolls it into view.
+// Search wraps around to the first event when the last event is reached. Events hidden by the filter are skipped.
+//
+// Returns false if there are no matching events.
+func (lv *LogView) SearchNext() bool {
+	defer lv.fireOnCurrentChange(lv.current)
+	lv.Lock()
+	defer lv.Unlock()
+
+	return lv.search(true)
+}
+
+// SearchPrev selects the previous event before the current one that matches the search and scrolls it into view.
+// Search wraps around to the last event when the first event is reached. Events hidden by the filter are skipped.
+//
+// Returns false if there are no matching events.
+func (lv *LogView) SearchPrev() bool {
+	defer lv.fireOnCurrentChange(lv.current)
+	lv.Lock()
+	defer lv.Unlock()
+
+	return lv.search(false)
+}
+
+// GetSearchMatchCount returns the position of the current event among the events matching the search and the total
+// number of matching events. Position starts with 1 and is 0 if the current event doesn't match the search.
+//
+// Only events that are not hidden by the filter are counted.
+func (lv *LogView) GetSearchMatchCount() (current int, total int) {
+	lv.RLock()
+	defer lv.RUnlock()
+
+	if lv.current == nil || len(lv.current.searchMatches) == 0 {
+		return 0, int(lv.searchMatchCount)
+	}
+	target := findFirstWrappedLine(lv.current)
+	for event := lv.firstVisible(); event != nil; event = lv.nextVisible(event) {
+		if event.order <= 1 && len(event.searchMatches) > 0 {
+			current++
+		}
+		if event == target {
+			break
+		}
+	}
+	return current, int(lv.searchMatchCount)
+}
+
+// *******************************
+// internal implementation details
+
+// search finds the next or previous matching event, wrapping around if needed, and makes it current
+func (lv *LogView) search(forward bool) bool {
+	if lv.hasSpilledHistory() {
+		return lv.searchHistory(forward)
+	}
+	if lv.searchMatchCount == 0 {
+		return false
+	}
+	start := lv.current
+	if start == nil {
+		start = lv.top
+	}
+	if start == nil {
+		return false
+	}
+	start = findFirstWrappedLine(start)
+
+	event := start
+	for {
+		if forward {
+			event = lv.nextVisible(findLastWrappedLine(event))
+			if event == nil {
+				event = lv.firstVisible()
+			}
+		} else {
+			event = lv.prevVisible(event)
+			if event == nil {
+				event = lv.lastVisible()
+			}
+			event = findFirstWrappedLine(event)
+		}
+		if len(event.searchMatches) > 0 {
+			break
+		}
+		if event == start {
+			return false
+		}
+	}
+
+	lv.following = false
+	lv.moveTo(event)
+	return true
+}
+
+// searchHistory finds the next or previous matching event among the events in memory and in the spill file, wrapping
+// around if needed, and makes it current. Events that are not in memory are paged in
+func (lv *LogView) searchHistory(forward bool) bool {
+	if lv.searchPattern == nil {
+		return false
+	}
+	first, last := lv.spill.baseSeq, lv.lastSeq()
+	if last < first {
+		return false
+	}
+	total := last - first + 1
+	start := first
+	if lv.current != nil {
+		start = lv.current.seq
+	} else if lv.top != nil {
+		start = lv.top.seq
+	}
+
+	for i := uint64(1); i <= total; i++ {
+		var seq uint64
+		if forward {
+			seq = first + (start-first+i)%total
+		} else {
+			seq = first + (start-first+total-i%total)%total
+		}
+		if event := lv.index.bySeq(seq); event != nil {
+			if event.filteredOut || len(event.searchMatches) == 0 {
+				continue
+			}
+		} else {
+			event = lv.readSpilled(seq)
+			if event == nil {
+				return false
+			}
+			if !lv.matchesFilter(event) || len(lv.findSearchMatches(event)) == 0 {
+				continue
+			}
+		}
+		event := lv.showEvent(seq)
+		if event == nil {
+			return false
+		}
+		lv.following = false
+		lv.moveTo(event)
+		return true
+	}
+	return false
+}
+
+// findSearchMatches returns all the ranges of event message that match the search pattern
+func (lv *LogView) findSearchMatches(event *logEventLine) []textRange {
+	if lv.searchPattern == nil {
+		return nil
+	}
+	match, err := lv.searchPattern.FindRunesMatch(event.Runes)
+	var matches []textRange
+	for err == nil && match != nil {
+		if match.Length > 0 {
+			matches = append(matches, textRange{start: match.Index, end: match.Index + match.Length})
+		}
+		match, err = lv.searchPattern.FindNextMatch(match)
+	}
+	return matches
+}
+
+// updateSearchMatches finds search matches for the unwrapped event and updates the number of matching events
+func (lv *LogView) updateSearchMatches(event *logEventLine) {
+	wasMatched := len(event.searchMatches) > 0
+	event.searchMatches = lv.findSearchMatches(event)
+	isMatched := len(event.searchMatches) > 0
+	if !event.filteredOut && wasMatched != isMatched {
+		if isMatched {
+			lv.searchMatchCount++
+		} else {
+			lv.searchMatchCount--
+		}
+	}
+}
+
+// researchLines finds search matches for all the events
+func (lv *LogView) researchLines() {
+	lv.searchMatchCount = 0
+	var matches []textRange
+	for event := lv.firstEvent; event != nil; event = event.next {
+		if event.order <= 1 {
+			matches = lv.findSearchMatches(event)
+			if len(matches) > 0 && !event.filteredOut {
+				lv.searchMatchCount++
+			}
+		}
+		event.searchMatches = matches
+	}
+}
+
+// applySearchStyle returns the search match style if the position is inside one of search matches of the event,
+// otherwise the style is returned unchanged. matchIndex keeps track of the last checked match, so positions must be
+// checked in increasing order
+func (lv *LogView) applySearchStyle(event *logEventLine, pos int, matchIndex *int, style tcell.Style) tcell.Style {
+	for *matchIndex < len(event.searchMatches) && event.searchMatches[*matchIndex].end <= pos {
+		*matchIndex++
+	}
+	if *matchIndex < len(event.searchMatches) && event.searchMatches[*matchIndex].start <= pos {
+		return lv.searchStyle
+	}
+	return style
+}
@@ -0,0 +1,92 @@
+package logview
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"testing"
+	"time"
+)
+
+func TestLogView_SearchNext(t *testing.T) {
+	lv := NewLogView()
+	lv.SetHighlightCurrentEvent(true)
+	ts := time.Now().Add(-24 * time.Hour)
+	lv.AppendEvents(randomEvents(100, ts))
+
+	lv.SetSearchText("#5")
+	lv.ScrollToTop()
+
+	current, total := lv.GetSearchMatchCount()
+	if current != 0 || total != 11 {
+		t.Errorf("Expected 0/11 matches, got %d/%d", current, total)
+	}
+
+	if !lv.SearchNext() || lv.GetCurrentEvent().EventID != "e5" {
+		t.Errorf("Failed to find next match, current=%s", lv.GetCurrentEvent().EventID)
+	}
+	if !lv.SearchNext() || lv.GetCurrentEvent().EventID != "e50" {
+		t.Errorf("Failed to find next match, current=%s", lv.GetCurrentEvent().EventID)
+	}
+	current, total = lv.GetSearchMatchCount()
+	if current != 2 || total != 11 {
+		t.Errorf("Expected 2/11 matches, got %d/%d", current, total)
+	}
+	if lv.IsFollowing() {
+		t.Errorf("Search must disable following")
+	}
+
+	lv.ScrollToBottom()
+	if !lv.SearchNext() || lv.GetCurrentEvent().EventID != "e5" {
+		t.Errorf("Search must wrap around to the first match, current=%s", lv.GetCurrentEvent().EventID)
+	}
+	if !lv.SearchPrev() || lv.GetCurrentEvent().EventID != "e59" {
+		t.Errorf("Search must wrap around to the last match, current=%s", lv.GetCurrentEvent().EventID)
+	}
+}
+
+func TestLogView_SearchFiltered(t *testing.T) {
+	lv := NewLogView()
+	lv.SetHighlightCurrentEvent(true)
+	ts := time.Now().Add(-24 * time.Hour)
+	lv.AppendEvents(randomEvents(100, ts))
+
+	if err := lv.SetSearchRegex(`#\d0$`); err != nil {
+		t.Fatalf("Failed to set search: %v", err)
+	}
+	if err := lv.SetFilterRegex(`#[3-6]\d$`); err != nil {
+		t.Fatalf("Failed to set filter: %v", err)
+	}
+	_, total := lv.GetSearchMatchCount()
+	if total != 4 {
+		t.Errorf("Only visible events should be counted, got %d", total)
+	}
+	lv.ScrollToTop()
+	if !lv.SearchNext() || lv.GetCurrentEvent().EventID != "e40" {
+		t.Errorf("Failed to find next visible match, current=%s", lv.GetCurrentEvent().EventID)
+	}
+
+	lv.ClearSearch()
+	if lv.IsSearchActive() || lv.SearchNext() {
+		t.Errorf("Search should have been cleared")
+	}
+}
+
+func TestLogView_SearchHighlight(t *testing.T) {
+	screen := tcell.NewSimulationScreen("UTF-8")
+	screen.Init()
+	screen.SetSize(20, 2)
+	lv := NewLogView()
+	lv.SetRect(0, 0, 20, 2)
+	searchStyle := tcell.StyleDefault.Background(tcell.ColorRed)
+	lv.SetSearchMatchStyle(searchStyle)
+	lv.SetSearchText("ab")
+	lv.AppendEvent(NewLogEvent("1", "xabyAB"))
+	lv.Draw(screen)
+	screen.Show()
+
+	for x, expected := range []bool{false, true, true, false, true, true} {
+		_, _, style, _ := screen.GetContent(x, 0)
+		if (style == searchStyle) != expected {
+			t.Errorf("Invalid search highlight at %d", x)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package logview
+
+import (
+	"fmt"
+	"github.com/gdamore/tcell/v2"
+	gui "github.com/rivo/tview"
+)
+
+// SearchableLogView is a LogView with a built-in vim-style search prompt.
+//
+// Pressing "/" or "?" (Keys.Search and Keys.SearchBackward) opens a one-line prompt at the bottom of the view.
+// Enter runs forward or backward search for the entered regular expression, "n" and "N" (Keys.SearchNext and
+// Keys.SearchPrev) repeat the last search in the same or in the opposite direction. Escape (Keys.Cancel) clears
+// the search. All other keys are handled by the LogView.
+//
+// While the search is active, the bottom line displays the search pattern and the match counter.
+type SearchableLogView struct {
+	*gui.Box
+
+	logView *LogView
+	prompt  *gui.InputField
+
+	promptVisible bool
+	backward      bool
+	pattern       string
+	message       string
+
+	statusStyle tcell.Style
+}
+
+// NewSearchableLogView returns a new log view with a search prompt
+func NewSearchableLogView() *SearchableLogView {
+	sv := &SearchableLogView{
+		Box:         gui.NewBox(),
+		logView:     NewLogView(),
+		prompt:      gui.NewInputField(),
+		statusStyle: tcell.StyleDefault.Foreground(gui.Styles.PrimaryTextColor).Background(gui.Styles.ContrastBackgroundColor),
+	}
+	sv.prompt.SetDoneFunc(func(key tcell.Key) {
+		sv.promptVisible = false
+		if key == tcell.KeyEnter && sv.prompt.GetText() != "" {
+			sv.pattern = sv.prompt.GetText()
+			sv.runSearch()
+		}
+	})
+	return sv
+}
+
+// GetLogView returns the underlying log view
+func (sv *SearchableLogView) GetLogView() *LogView {
+	return sv.logView
+}
+
+// GetPrompt returns the input field used as a search prompt, it can be used to customize the prompt appearance
+func (sv *SearchableLogView) GetPrompt() *gui.InputField {
+	return sv.prompt
+}
+
+// SetStatusStyle sets the style of the status line displaying the search pattern and match counter
+func (sv *SearchableLogView) SetStatusStyle(style tcell.Style) {
+	sv.statusStyle = style
+}
+
+// Search runs search for the regular expression in the given direction, the same way as if it was entered into the
+// search prompt
+func (sv *SearchableLogView) Search(pattern string, backward bool) {
+	sv.pattern = pattern
+	sv.backward = backward
+	sv.runSearch()
+}
+
+// Draw draws this primitive onto the screen.
+func (sv *SearchableLogView) Draw(screen tcell.Screen) {
+	sv.Box.DrawForSubclass(screen, sv)
+
+	x, y, width, height := sv.GetInnerRect()
+	if (sv.promptVisible || sv.message != "" || sv.logView.IsSearchActive()) && height > 1 {
+		height--
+		if sv.promptVisible {
+			sv.prompt.SetRect(x, y+height, width, 1)
+			sv.prompt.Draw(screen)
+		} else {
+			sv.drawStatus(screen, x, y+height, width)
+		}
+	}
+	sv.logView.SetRect(x, y, width, height)
+	sv.logView.Draw(screen)
+}
+
+// Focus is called when this primitive receives focus.
+func (sv *SearchableLogView) Focus(delegate func(p gui.Primitive)) {
+	if sv.promptVisible {
+		delegate(sv.prompt)
+	} else {
+		delegate(sv.logView)
+	}
+}
+
+// HasFocus returns whether or not this primitive has focus.
+func (sv *SearchableLogView) HasFocus() bool {
+	return sv.prompt.HasFocus() || sv.logView.HasFocus()
+}
+
+// InputHandler returns the handler for this primitive.
+func (sv *SearchableLogView) InputHandler() func(event *tcell.EventKey, setFocus func(p gui.Primitive)) {
+	return sv.WrapInputHandler(func(event *tcell.EventKey, setFocus func(p gui.Primitive)) {
+		if sv.promptVisible {
+			if handler := sv.prompt.InputHandler(); handler != nil {
+				handler(event, setFocus)
+			}
+			if !sv.promptVisible {
+				setFocus(sv.logView)
+			}
+			return
+		}
+
+		if sv.logView.isMarkPending() {
+			// the key is the name of the mark
+			sv.logView.InputHandler()(event, setFocus)
+		} else if HitShortcut(event, Keys.Search) {
+			sv.openPrompt(false, setFocus)
+		} else if HitShortcut(event, Keys.SearchBackward) {
+			sv.openPrompt(true, setFocus)
+		} else if HitShortcut(event, Keys.SearchNext) {
+			sv.repeatSearch(false)
+		} else if HitShortcut(event, Keys.SearchPrev) {
+			sv.repeatSearch(true)
+		} else if HitShortcut(event, Keys.Cancel) {
+			sv.message = ""
+			sv.logView.ClearSearch()
+			sv.logView.ClearSelection()
+		} else if handler := sv.logView.InputHandler(); handler != nil {
+			handler(event, setFocus)
+		}
+	})
+}
+
+// MouseHandler returns the mouse handler for this primitive.
+func (sv *SearchableLogView) MouseHandler() func(action gui.MouseAction, event *tcell.EventMouse, setFocus func(p gui.Primitive)) (consumed bool, capture gui.Primitive) {
+	return sv.WrapMouseHandler(func(action gui.MouseAction, event *tcell.EventMouse, setFocus func(p gui.Primitive)) (consumed bool, capture gui.Primitive) {
+		if !sv.InRect(event.Position()) {
+			return false, nil
+		}
+		if sv.promptVisible {
+			consumed, capture = sv.prompt.MouseHandler()(action, event, setFocus)
+			if consumed {
+				return
+			}
+		}
+		consumed, capture = sv.logView.MouseHandler()(action, event, setFocus)
+		if consumed && action == gui.MouseLeftClick {
+			sv.promptVisible = false
+		}
+		return
+	})
+}
+
+// *******************************
+// internal implementation details
+
+func (sv *SearchableLogView) openPrompt(backward bool, setFocus func(p gui.Primitive)) {
+	sv.backward = backward
+	sv.promptVisible = true
+	sv.message = ""
+	sv.prompt.SetLabel(sv.promptPrefix())
+	sv.prompt.SetText("")
+	setFocus(sv.prompt)
+}
+
+func (sv *SearchableLogView) promptPrefix() string {
+	if sv.backward {
+		return "?"
+	}
+	return "/"
+}
+
+func (sv *SearchableLogView) runSearch() {
+	if err := sv.logView.SetSearchRegex(sv.pattern); err != nil {
+		sv.logView.ClearSearch()
+		sv.message = "Invalid pattern: " + sv.pattern
+		return
+	}
+	sv.repeatSearch(false)
+}
+
+// repeatSearch searches in the direction of the last search or in the opposite direction if reverse is true
+func (sv *SearchableLogView) repeatSearch(reverse bool) {
+	if !sv.logView.IsSearchActive() {
+		return
+	}
+	var found bool
+	if sv.backward != reverse {
+		found = sv.logView.SearchPrev()
+	} else {
+		found = sv.logView.SearchNext()
+	}
+	if found {
+		sv.message = ""
+	} else {
+		sv.message = "Pattern not found: " + sv.pattern
+	}
+}
+
+func (sv *SearchableLogView) drawStatus(screen tcell.Screen, x, y, width int) {
+	for i := 0; i < width; i++ {
+		screen.SetCell(x+i, y, sv.statusStyle, ' ')
+	}
+	var left, right string
+	if sv.message != "" {
+		left = sv.message
+	} else {
+		left = sv.promptPrefix() + sv.pattern
+		current, total := sv.logView.GetSearchMatchCount()
+		right = fmt.Sprintf("%d/%d", current, total)
+	}
+	rightWidth := runesWidth([]rune(right))
+	if rightWidth > 0 {
+		if rightWidth+1 > width {
+			return
+		}
+		width -= rightWidth + 1
+		printString(screen, x+width+1, y, right, sv.statusStyle)
+	}
+	printRunes(screen, x, y, width, []rune(left), func(int) tcell.Style {
+		return sv.statusStyle
+	})
+}
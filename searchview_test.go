@@ -0,0 +1,67 @@
+package logview
+
+import (
+	"github.com/gdamore/tcell/v2"
+	gui "github.com/rivo/tview"
+	"strings"
+	"testing"
+	"time"
+)
+
+func typeKeys(sv *SearchableLogView, keys string) {
+	handler := sv.InputHandler()
+	for _, k := range keys {
+		handler(tcell.NewEventKey(tcell.KeyRune, k, tcell.ModNone), func(p gui.Primitive) {})
+	}
+}
+
+func TestSearchableLogView_Search(t *testing.T) {
+	screen := tcell.NewSimulationScreen("UTF-8")
+	screen.Init()
+	screen.SetSize(40, 10)
+
+	sv := NewSearchableLogView()
+	sv.SetRect(0, 0, 40, 10)
+	lv := sv.GetLogView()
+	lv.SetHighlightCurrentEvent(true)
+	lv.AppendEvents(randomEvents(100, time.Now()))
+	lv.ScrollToTop()
+
+	typeKeys(sv, "/#[4]2")
+	if !sv.promptVisible || sv.prompt.GetText() != "#[4]2" {
+		t.Fatalf("Search prompt should be open, text=%s", sv.prompt.GetText())
+	}
+	sv.InputHandler()(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), func(p gui.Primitive) {})
+	if sv.promptVisible {
+		t.Errorf("Search prompt should be closed")
+	}
+	if lv.GetCurrentEvent().EventID != "e42" {
+		t.Errorf("Search should select e42, got %s", lv.GetCurrentEvent().EventID)
+	}
+
+	sv.Draw(screen)
+	screen.Show()
+	status := screenLine(screen, 9)
+	if !strings.HasPrefix(status, "/#[4]2 ") || !strings.HasSuffix(status, " 1/1") || len(status) != 40 {
+		t.Errorf("Invalid status line: '%s'", status)
+	}
+
+	typeKeys(sv, "?Event #1")
+	sv.InputHandler()(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), func(p gui.Primitive) {})
+	if lv.GetCurrentEvent().EventID != "e19" {
+		t.Errorf("Backward search should select e19, got %s", lv.GetCurrentEvent().EventID)
+	}
+	typeKeys(sv, "n")
+	if lv.GetCurrentEvent().EventID != "e18" {
+		t.Errorf("Repeated backward search should select e18, got %s", lv.GetCurrentEvent().EventID)
+	}
+	typeKeys(sv, "N")
+	if lv.GetCurrentEvent().EventID != "e19" {
+		t.Errorf("Reversed search should select e19, got %s", lv.GetCurrentEvent().EventID)
+	}
+
+	sv.InputHandler()(tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone), func(p gui.Primitive) {})
+	if lv.IsSearchActive() {
+		t.Errorf("Escape should clear the search")
+	}
+}
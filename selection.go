@@ -0,0 +1,243 @@
+package logview
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"strings"
+)
+
+// OnSelectionChanged is fired when the range of selected events changes. first and last are the first and the last
+// selected events, both are nil when the selection is cleared. Use GetSelectedEvents to get all the selected events
+type OnSelectionChanged func(first *LogEvent, last *LogEvent)
+
+// SetOnSelectionChanged sets the listener that is notified when the range of selected events changes
+func (lv *LogView) SetOnSelectionChanged(listener OnSelectionChanged) {
+	lv.Lock()
+	defer lv.Unlock()
+
+	lv.onSelectionChanged = listener
+}
+
+// ExtendSelectionUp moves the current event one line up and extends the selection to it. If nothing is selected,
+// selection starts at the current event. Bound to Shift+Up by default
+func (lv *LogView) ExtendSelectionUp() {
+	defer lv.fireOnSelectionChange(lv.getSelection())
+	defer lv.fireOnCurrentChange(lv.current)
+	lv.Lock()
+	defer lv.Unlock()
+
+	lv.extendSelection(false)
+}
+
+// ExtendSelectionDown moves the current event one line down and extends the selection to it. If nothing is selected,
+// selection starts at the current event. Bound to Shift+Down by default
+func (lv *LogView) ExtendSelectionDown() {
+	defer lv.fireOnSelectionChange(lv.getSelection())
+	defer lv.fireOnCurrentChange(lv.current)
+	lv.Lock()
+	defer lv.Unlock()
+
+	lv.extendSelection(true)
+}
+
+// ClearSelection deselects all the events
+func (lv *LogView) ClearSelection() {
+	defer lv.fireOnSelectionChange(lv.getSelection())
+	lv.Lock()
+	defer lv.Unlock()
+
+	lv.selection.active = false
+}
+
+// HasSelection returns true if a range of events is selected
+func (lv *LogView) HasSelection() bool {
+	lv.RLock()
+	defer lv.RUnlock()
+
+	return lv.selection.active
+}
+
+// GetSelectedEvents returns the selected events in the order they were appended. Events hidden by the filter are not
+// included. Events that were paged out to the spill file are read from the file
+func (lv *LogView) GetSelectedEvents() []*LogEvent {
+	lv.Lock()
+	defer lv.Unlock()
+
+	lines := lv.selectedEvents()
+	events := make([]*LogEvent, len(lines))
+	for i, event := range lines {
+		events[i] = event.AsLogEvent()
+	}
+	return events
+}
+
+// CopySelection copies the messages of the selected events, or the current event if nothing is selected, to the
+// system clipboard, one event per line. Clipboard is set with OSC 52 escape sequence through the screen the log view
+// was last drawn on, so it works over SSH if the terminal supports it. Bound to 'y' by default.
+//
+// Returns false if there is nothing to copy or the log view was never drawn
+func (lv *LogView) CopySelection() bool {
+	lv.Lock()
+	defer lv.Unlock()
+
+	return lv.copySelection()
+}
+
+// SetSelectionBgColor sets the background color of the selected events. Current event is highlighted with the
+// current event background color (see SetCurrentBgColor) even if it is selected
+func (lv *LogView) SetSelectionBgColor(color tcell.Color) {
+	lv.Lock()
+	defer lv.Unlock()
+
+	lv.selectionBgColor = color
+}
+
+// *******************************
+// internal implementation details
+
+// selection is a range of events between the anchor, where the selection started, and the head, which moves with the
+// current event. Both ends are sequence numbers, so the selection survives re-wrapping
+type selection struct {
+	anchor uint64
+	head   uint64
+	active bool
+}
+
+// bounds returns the sequence numbers of the first and the last selected events
+func (s selection) bounds() (uint64, uint64) {
+	if s.anchor > s.head {
+		return s.head, s.anchor
+	}
+	return s.anchor, s.head
+}
+
+func (s selection) same(other selection) bool {
+	if s.active != other.active {
+		return false
+	}
+	if !s.active {
+		return true
+	}
+	from, to := s.bounds()
+	otherFrom, otherTo := other.bounds()
+	return from == otherFrom && to == otherTo
+}
+
+func (lv *LogView) getSelection() selection {
+	lv.RLock()
+	defer lv.RUnlock()
+
+	return lv.selection
+}
+
+func (lv *LogView) fireOnSelectionChange(old selection) {
+	lv.RLock()
+	listener := lv.onSelectionChanged
+	current := lv.selection
+	lv.RUnlock()
+	if listener == nil || current.same(old) {
+		return
+	}
+
+	var first, last *LogEvent
+	if current.active {
+		// selected events might have to be read from the spill file, which changes its state
+		lv.Lock()
+		if lv.selection.active {
+			from, to := lv.selection.bounds()
+			first = lv.historyEvent(from).AsLogEvent()
+			last = lv.historyEvent(to).AsLogEvent()
+		}
+		lv.Unlock()
+	}
+	listener(first, last)
+}
+
+func (lv *LogView) extendSelection(forward bool) {
+	if lv.current == nil {
+		return
+	}
+	if !lv.selection.active {
+		lv.selection = selection{anchor: lv.current.seq, head: lv.current.seq, active: true}
+	}
+	if forward {
+		lv.scrollOneDown()
+	} else {
+		lv.scrollOneUp()
+	}
+	lv.selection.head = lv.current.seq
+}
+
+// startSelection sets the anchor of a new selection at the line, selection becomes active when it is extended
+func (lv *LogView) startSelection(line *logEventLine) {
+	lv.selection.active = false
+	if line != nil {
+		lv.selection.anchor = line.seq
+	}
+}
+
+// dragSelection extends the selection to the line at a given offset from the top of the page, scrolling the page if
+// the offset is outside it
+func (lv *LogView) dragSelection(offset int) {
+	if offset < 0 {
+		lv.scrollOneUp()
+		lv.current = lv.top
+	} else if offset >= lv.pageHeight {
+		lv.scrollOneDown()
+	} else {
+		lv.current = lv.atOffset(lv.top, offset)
+	}
+	if lv.current != nil {
+		lv.selection.head = lv.current.seq
+		lv.selection.active = true
+	}
+}
+
+func (lv *LogView) isSelected(event *logEventLine) bool {
+	if !lv.selection.active {
+		return false
+	}
+	from, to := lv.selection.bounds()
+	return event.seq >= from && event.seq <= to
+}
+
+// selectedEvents returns the visible selected events from memory or from the spill file
+func (lv *LogView) selectedEvents() []*logEventLine {
+	if !lv.selection.active {
+		return nil
+	}
+	var events []*logEventLine
+	from, to := lv.selection.bounds()
+	for seq := from; seq <= to; seq++ {
+		if event := lv.visibleEvent(seq); event != nil {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+func (lv *LogView) copySelection() bool {
+	events := lv.selectedEvents()
+	if len(events) == 0 && lv.current != nil {
+		events = []*logEventLine{findFirstWrappedLine(lv.current)}
+	}
+	if len(events) == 0 || lv.screen == nil {
+		return false
+	}
+	messages := make([]string, len(events))
+	for i, event := range events {
+		messages[i] = event.message()
+	}
+	lv.screen.SetClipboard([]byte(strings.Join(messages, "\n")))
+	return true
+}
+
+// lineBackground returns the background color of the line if it is the current line or it is selected
+func (lv *LogView) lineBackground(event *logEventLine) (tcell.Color, bool) {
+	if lv.highlightCurrent && event == lv.current {
+		return lv.currentBgColor, true
+	}
+	if lv.isSelected(event) {
+		return lv.selectionBgColor, true
+	}
+	return tcell.ColorDefault, false
+}
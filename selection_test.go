@@ -0,0 +1,170 @@
+package logview
+
+import (
+	"fmt"
+	"github.com/gdamore/tcell/v2"
+	gui "github.com/rivo/tview"
+	"testing"
+)
+
+func newSelectionLogView(screen tcell.SimulationScreen) *LogView {
+	screen.Init()
+	screen.SetSize(40, 5)
+	lv := NewLogView()
+	lv.SetRect(0, 0, 40, 5)
+	lv.SetHighlightCurrentEvent(true)
+	lv.Draw(screen)
+	for i := 0; i < 10; i++ {
+		lv.AppendEvent(NewLogEvent(fmt.Sprint(i), fmt.Sprintf("event %d", i)))
+	}
+	lv.ScrollToTop()
+	return lv
+}
+
+func selectedIDs(lv *LogView) string {
+	ids := ""
+	for _, event := range lv.GetSelectedEvents() {
+		ids += event.EventID
+	}
+	return ids
+}
+
+func TestLogView_KeyboardSelection(t *testing.T) {
+	screen := tcell.NewSimulationScreen("UTF-8")
+	lv := newSelectionLogView(screen)
+	var first, last *LogEvent
+	changes := 0
+	lv.SetOnSelectionChanged(func(f *LogEvent, l *LogEvent) {
+		first, last = f, l
+		changes++
+	})
+
+	lv.ScrollToEventID("2")
+	for i := 0; i < 3; i++ {
+		lv.InputHandler()(tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModShift), func(p gui.Primitive) {})
+	}
+	if !lv.HasSelection() || selectedIDs(lv) != "2345" || lv.current.EventID != "5" {
+		t.Errorf("Invalid selection '%s', current %s", selectedIDs(lv), lv.current.EventID)
+	}
+	if changes != 3 || first.EventID != "2" || last.EventID != "5" {
+		t.Errorf("Invalid selection change notification: %d changes, %v - %v", changes, first, last)
+	}
+
+	// selection can be extended past the anchor
+	for i := 0; i < 5; i++ {
+		lv.ExtendSelectionUp()
+	}
+	if selectedIDs(lv) != "012" || first.EventID != "0" || last.EventID != "2" {
+		t.Errorf("Invalid selection '%s'", selectedIDs(lv))
+	}
+
+	lv.SetFilterRegex("event [^1]")
+	if selectedIDs(lv) != "02" {
+		t.Errorf("Hidden events must not be selected: '%s'", selectedIDs(lv))
+	}
+	lv.ClearFilter()
+
+	lv.InputHandler()(tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone), func(p gui.Primitive) {})
+	if lv.HasSelection() || first != nil || last != nil {
+		t.Errorf("Selection must be cleared")
+	}
+}
+
+func TestLogView_SelectionEviction(t *testing.T) {
+	screen := tcell.NewSimulationScreen("UTF-8")
+	lv := newSelectionLogView(screen)
+	var first, last *LogEvent
+	lv.SetOnSelectionChanged(func(f *LogEvent, l *LogEvent) {
+		first, last = f, l
+	})
+	lv.ScrollToEventID("1")
+	lv.ExtendSelectionDown()
+	lv.ExtendSelectionDown()
+
+	// anchor of the selection is evicted, selection starts at the oldest remaining event
+	lv.SetMaxEvents(9)
+	lv.AppendEvent(NewLogEvent("10", "event 10"))
+	if selectedIDs(lv) != "23" || first == nil || first.EventID != "2" || last.EventID != "3" {
+		t.Errorf("Invalid selection after eviction '%s', %v - %v", selectedIDs(lv), first, last)
+	}
+
+	lv.AppendEvents([]*LogEvent{NewLogEvent("11", "event 11"), NewLogEvent("12", "event 12")})
+	if lv.HasSelection() || first != nil || last != nil {
+		t.Errorf("Selection must be cleared when all the selected events are evicted")
+	}
+
+	lv.ScrollToEventID("11")
+	lv.ExtendSelectionDown()
+	lv.Clear()
+	if lv.HasSelection() || first != nil || last != nil {
+		t.Errorf("Selection must be cleared together with the events")
+	}
+}
+
+func TestLogView_DrawSelection(t *testing.T) {
+	screen := tcell.NewSimulationScreen("UTF-8")
+	lv := newSelectionLogView(screen)
+	lv.ExtendSelectionDown()
+	lv.ExtendSelectionDown()
+	lv.Draw(screen)
+
+	_, defaultBg, _ := lv.defaultStyle.Decompose()
+	for y, expected := range []tcell.Color{tcell.ColorDarkSlateBlue, tcell.ColorDarkSlateBlue, tcell.ColorDimGray, defaultBg} {
+		_, _, style, _ := screen.GetContent(1, y)
+		if _, bg, _ := style.Decompose(); bg != expected {
+			t.Errorf("Invalid background of line %d: %v, expected %v", y, bg, expected)
+		}
+	}
+}
+
+func TestLogView_MouseSelection(t *testing.T) {
+	screen := tcell.NewSimulationScreen("UTF-8")
+	lv := newSelectionLogView(screen)
+	handler := lv.MouseHandler()
+	mouse := func(action gui.MouseAction, y int) {
+		handler(action, tcell.NewEventMouse(1, y, tcell.ButtonPrimary, tcell.ModNone), func(p gui.Primitive) {})
+	}
+
+	mouse(gui.MouseLeftDown, 1)
+	mouse(gui.MouseMove, 3)
+	mouse(gui.MouseLeftUp, 3)
+	if selectedIDs(lv) != "123" || lv.current.EventID != "3" {
+		t.Errorf("Invalid selection '%s', current %s", selectedIDs(lv), lv.current.EventID)
+	}
+
+	// dragging below the page scrolls it
+	mouse(gui.MouseLeftDown, 3)
+	mouse(gui.MouseMove, 7)
+	mouse(gui.MouseMove, 7)
+	mouse(gui.MouseLeftUp, 7)
+	if selectedIDs(lv) != "345" || lv.top.EventID != "1" {
+		t.Errorf("Invalid selection '%s', top %s", selectedIDs(lv), lv.top.EventID)
+	}
+
+	mouse(gui.MouseLeftDown, 0)
+	mouse(gui.MouseLeftUp, 0)
+	mouse(gui.MouseLeftClick, 0)
+	if lv.HasSelection() {
+		t.Errorf("Click must clear the selection")
+	}
+}
+
+func TestLogView_CopySelection(t *testing.T) {
+	screen := tcell.NewSimulationScreen("UTF-8")
+	lv := newSelectionLogView(screen)
+	lv.ScrollToEventID("4")
+	if !lv.CopySelection() || string(screen.GetClipboardData()) != "event 4" {
+		t.Errorf("Current event must be copied: '%s'", screen.GetClipboardData())
+	}
+
+	lv.ExtendSelectionDown()
+	lv.ExtendSelectionDown()
+	lv.InputHandler()(tcell.NewEventKey(tcell.KeyRune, 'y', tcell.ModNone), func(p gui.Primitive) {})
+	if string(screen.GetClipboardData()) != "event 4\nevent 5\nevent 6" {
+		t.Errorf("Selection must be copied: '%s'", screen.GetClipboardData())
+	}
+
+	if NewLogView().CopySelection() {
+		t.Errorf("Nothing to copy before the log view is drawn")
+	}
+}
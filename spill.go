@@ -0,0 +1,628 @@
+package logview
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"github.com/gdamore/tcell/v2"
+	"io"
+	"os"
+	"time"
+)
+
+// spillPageSize is the largest number of events read from the spill file at once when the log view is scrolled past
+// the events held in memory
+const spillPageSize = 256
+
+// spillIndexStep is the number of records in the spill file per offset kept in memory
+const spillIndexStep = 64
+
+var errCorruptSpillRecord = errors.New("corrupt spill file record")
+
+// SetSpillFile enables disk-backed history. Events evicted by the event limit (see SetEventLimit) are written to an
+// append-only file at a given path instead of being dropped, and are paged back in when the log view is scrolled past
+// the oldest event in memory. Search and ScrollToTimestamp span the events both in memory and on disk.
+//
+// While older events are paged in, the newest events are moved out of memory, so the number of events in memory never
+// exceeds the limit. Events appended meanwhile are written directly to the file, they are paged in again when the log
+// view is scrolled down to them or to the end.
+//
+// Events on disk still take some memory: the timestamp of every spilled event is kept for ScrollToTimestamp, which
+// is 32 bytes per event, and the file offset of every 64th event.
+//
+// The file is truncated when it is opened and when the log view is cleared. Empty path disables spilling and closes
+// the file. Default is disabled
+func (lv *LogView) SetSpillFile(path string) error {
+	lv.Lock()
+	defer lv.Unlock()
+
+	var err error
+	if lv.spill != nil {
+		if lv.isDetached() {
+			lv.showEvent(lv.lastSeq())
+		}
+		if lv.spill.detached {
+			lv.attach()
+		}
+		err = lv.spill.close()
+		lv.spill = nil
+	}
+	if path == "" {
+		return err
+	}
+	lv.spill, err = openSpillStore(path, lv.index.firstSeq)
+	return err
+}
+
+// GetSpilledEventCount returns the number of events written to the spill file
+func (lv *LogView) GetSpilledEventCount() uint {
+	lv.RLock()
+	defer lv.RUnlock()
+
+	if lv.spill == nil {
+		return 0
+	}
+	return uint(lv.spill.count)
+}
+
+// GetSpillError returns the error that occurred while reading or writing the spill file. After an error the events
+// on disk are no longer paged in and evicted events are dropped
+func (lv *LogView) GetSpillError() error {
+	lv.RLock()
+	defer lv.RUnlock()
+
+	if lv.spill == nil {
+		return nil
+	}
+	return lv.spill.err
+}
+
+// *******************************
+// internal implementation details
+
+// spillStore is an append-only file of the events evicted from memory. Each record is the length of the encoded event
+// followed by the event. Events are written in the order of their sequence numbers and without gaps, so the record of
+// the event is found by its sequence number: only the offset of every spillIndexStep-th record is kept in memory, the
+// records in between are skipped by their lengths
+type spillStore struct {
+	file    *os.File
+	writer  *bufio.Writer
+	size    int64
+	baseSeq uint64  // sequence number of the first event in the file
+	count   uint64  // number of records in the file
+	offsets []int64 // offsets of every spillIndexStep-th record, starting with the event with baseSeq
+
+	// position after the last read record, so the events read in order don't skip the records from the indexed one
+	cursorSeq    uint64
+	cursorOffset int64
+
+	timestamps timestampIndex
+	buf        []byte
+	err        error
+
+	// the log view is detached when the events in memory don't include the newest event. The newest event is kept in
+	// pending instead of the file, so the following messages can still be concatenated to it
+	detached bool
+	pending  *logEventLine
+}
+
+func openSpillStore(path string, baseSeq uint64) (*spillStore, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return &spillStore{
+		file:    file,
+		writer:  bufio.NewWriter(file),
+		baseSeq: baseSeq,
+	}, nil
+}
+
+func (s *spillStore) close() error {
+	err := s.writer.Flush()
+	if closeErr := s.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// reset truncates the file, the next written event gets sequence number 0
+func (s *spillStore) reset() {
+	s.writer.Reset(s.file)
+	s.err = s.file.Truncate(0)
+	s.size = 0
+	s.baseSeq = 0
+	s.count = 0
+	s.offsets = nil
+	s.cursorSeq, s.cursorOffset = 0, 0
+	s.timestamps = timestampIndex{}
+	s.detached = false
+	s.pending = nil
+}
+
+// nextSeq returns the sequence number of the next event written to the file
+func (s *spillStore) nextSeq() uint64 {
+	return s.baseSeq + s.count
+}
+
+func (s *spillStore) write(event *logEventLine) error {
+	s.buf = encodeEvent(s.buf[:0], event)
+	var header [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(header[:], uint64(len(s.buf)))
+	if _, err := s.writer.Write(header[:n]); err != nil {
+		return err
+	}
+	if _, err := s.writer.Write(s.buf); err != nil {
+		return err
+	}
+	if s.count%spillIndexStep == 0 {
+		s.offsets = append(s.offsets, s.size)
+	}
+	s.count++
+	s.size += int64(n + len(s.buf))
+	s.timestamps.insert(timestampEntry{timestamp: event.Timestamp, seq: event.seq})
+	return nil
+}
+
+// read returns the unwrapped event with a given sequence number, the event is not linked to the list
+func (s *spillStore) read(seq uint64) (*logEventLine, error) {
+	if seq < s.baseSeq || seq >= s.nextSeq() {
+		return nil, io.EOF
+	}
+	if s.writer.Buffered() > 0 {
+		if err := s.writer.Flush(); err != nil {
+			return nil, err
+		}
+	}
+	i := seq - s.baseSeq
+	from, offset := s.baseSeq+i/spillIndexStep*spillIndexStep, s.offsets[i/spillIndexStep]
+	if s.cursorSeq > from && s.cursorSeq <= seq {
+		from, offset = s.cursorSeq, s.cursorOffset
+	}
+	reader := bufio.NewReader(io.NewSectionReader(s.file, offset, s.size-offset))
+	var record []byte
+	for ; ; from++ {
+		length, err := binary.ReadUvarint(reader)
+		if err != nil {
+			return nil, err
+		}
+		offset += int64(uvarintLen(length))
+		if length > uint64(s.size-offset) {
+			return nil, errCorruptSpillRecord
+		}
+		offset += int64(length)
+		if from == seq {
+			record = make([]byte, length)
+			if _, err = io.ReadFull(reader, record); err != nil {
+				return nil, err
+			}
+			break
+		}
+		if _, err = reader.Discard(int(length)); err != nil {
+			return nil, err
+		}
+	}
+	s.cursorSeq, s.cursorOffset = seq+1, offset
+	event, err := decodeEvent(record)
+	if err != nil {
+		return nil, err
+	}
+	event.seq = seq
+	return event, nil
+}
+
+// uvarintLen returns the length of the varint encoded value
+func uvarintLen(x uint64) int {
+	n := 1
+	for ; x >= 0x80; x >>= 7 {
+		n++
+	}
+	return n
+}
+
+func encodeEvent(buf []byte, event *logEventLine) []byte {
+	buf = appendString(buf, event.EventID)
+	buf = appendString(buf, event.Source)
+	// binary form of the timestamp keeps the zone offset, so the timestamp is displayed the same way after it is read
+	timestamp, err := event.Timestamp.MarshalBinary()
+	if err != nil { // zone offset cannot be encoded
+		timestamp, _ = event.Timestamp.UTC().MarshalBinary()
+	}
+	buf = binary.AppendUvarint(buf, uint64(len(timestamp)))
+	buf = append(buf, timestamp...)
+	buf = binary.AppendUvarint(buf, uint64(event.Level))
+	buf = appendString(buf, string(event.Runes))
+	buf = binary.AppendUvarint(buf, uint64(len(event.Fields)))
+	for name, value := range event.Fields {
+		buf = appendString(buf, name)
+		buf = appendString(buf, value)
+	}
+	buf = binary.AppendUvarint(buf, uint64(len(event.ansiSpans)))
+	for _, span := range event.ansiSpans {
+		buf = binary.AppendUvarint(buf, uint64(span.start))
+		buf = binary.AppendUvarint(buf, uint64(span.end-span.start))
+		buf = binary.AppendUvarint(buf, uint64(span.style.fg))
+		buf = binary.AppendUvarint(buf, uint64(span.style.bg))
+		buf = binary.AppendUvarint(buf, uint64(span.style.attrs))
+	}
+	buf = appendString(buf, event.original)
+	return buf
+}
+
+func decodeEvent(data []byte) (*logEventLine, error) {
+	r := recordReader{data: data}
+	event := &logEventLine{
+		EventID: r.string(),
+		Source:  r.string(),
+	}
+	if err := event.Timestamp.UnmarshalBinary(r.bytes()); err != nil && r.err == nil {
+		r.err = errCorruptSpillRecord
+	}
+	event.Level = LogLevel(r.uvarint())
+	event.Runes = []rune(r.string())
+	if count := r.uvarint(); count > 0 && r.err == nil {
+		event.Fields = make(map[string]string)
+		for i := uint64(0); i < count && r.err == nil; i++ {
+			name := r.string()
+			event.Fields[name] = r.string()
+		}
+	}
+	for count := r.uvarint(); count > 0 && r.err == nil; count-- {
+		var span ansiSpan
+		span.start = int(r.uvarint())
+		span.end = span.start + int(r.uvarint())
+		span.style.fg = tcell.Color(r.uvarint())
+		span.style.bg = tcell.Color(r.uvarint())
+		span.style.attrs = tcell.AttrMask(r.uvarint())
+		event.ansiSpans = append(event.ansiSpans, span)
+	}
+	event.original = r.string()
+	if r.err != nil {
+		return nil, r.err
+	}
+	event.lineCount = 1
+	event.end = len(event.Runes)
+	event.hasNewLines = containsNewLine(event.Runes)
+	return event, nil
+}
+
+func appendString(buf []byte, s string) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(s)))
+	return append(buf, s...)
+}
+
+// recordReader decodes the values of the spill file record, the first error is kept and the following reads return
+// zero values
+type recordReader struct {
+	data []byte
+	err  error
+}
+
+func (r *recordReader) uvarint() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	value, n := binary.Uvarint(r.data)
+	if n <= 0 {
+		r.err = errCorruptSpillRecord
+		return 0
+	}
+	r.data = r.data[n:]
+	return value
+}
+
+func (r *recordReader) string() string {
+	return string(r.bytes())
+}
+
+func (r *recordReader) bytes() []byte {
+	length := r.uvarint()
+	if r.err != nil || uint64(len(r.data)) < length {
+		r.err = errCorruptSpillRecord
+		return nil
+	}
+	b := r.data[:length]
+	r.data = r.data[length:]
+	return b
+}
+
+// isDetached returns true if the newest events are not in memory
+func (lv *LogView) isDetached() bool {
+	return lv.spill != nil && lv.spill.detached
+}
+
+// hasSpilledHistory returns true if there are events that can be paged in from the spill file
+func (lv *LogView) hasSpilledHistory() bool {
+	return lv.spill != nil && lv.spill.err == nil && (lv.spill.detached || lv.index.firstSeq > lv.spill.baseSeq)
+}
+
+// lastSeq returns the sequence number of the newest event, in memory or not
+func (lv *LogView) lastSeq() uint64 {
+	if !lv.isDetached() {
+		return lv.index.firstSeq + uint64(lv.index.count) - 1
+	}
+	if lv.spill.pending != nil {
+		return lv.spill.pending.seq
+	}
+	return lv.spill.nextSeq() - 1
+}
+
+// spillFailed stops spilling after the error. Pending event is returned to memory, so the log view shows new events
+func (lv *LogView) spillFailed(err error) {
+	lv.spill.err = err
+	if lv.spill.detached {
+		lv.attach()
+	}
+}
+
+// spillEvent writes the event that is about to be evicted from memory, unless it is already in the file
+func (lv *LogView) spillEvent(event *logEventLine) {
+	if lv.spill == nil || lv.spill.err != nil || event == nil || event.seq != lv.spill.nextSeq() {
+		return
+	}
+	if err := lv.spill.write(event); err != nil {
+		lv.spillFailed(err)
+	}
+}
+
+// readSpilled returns the event with a given sequence number from the spill file or a copy of the pending event, which
+// is still changed when the following messages are concatenated to it
+func (lv *LogView) readSpilled(seq uint64) *logEventLine {
+	if pending := lv.spill.pending; pending != nil && pending.seq == seq {
+		event := pending.copy()
+		event.previous, event.next = nil, nil
+		return event
+	}
+	event, err := lv.spill.read(seq)
+	if err != nil {
+		lv.spillFailed(err)
+		return nil
+	}
+	// tab width or ANSI mode might have changed since the event was written
+	lv.reparseMessage(event)
+	return event
+}
+
+// appendDetached adds the event while the log view is detached. The previous pending event is written to the spill
+// file and the new one becomes pending. Returns false if the event has to be appended to memory
+func (lv *LogView) appendDetached(logEvent *LogEvent, message []rune, ansiSpans []ansiSpan, continuation bool) bool {
+	s := lv.spill
+	if continuation && s.pending != nil {
+		concatenateMessage(s.pending, logEvent.Message, message, ansiSpans)
+		s.pending.end = len(s.pending.Runes)
+		return true
+	}
+	if s.pending != nil {
+		if err := s.write(s.pending); err != nil {
+			lv.spillFailed(err)
+			return false
+		}
+	}
+	s.pending = newEventLine(logEvent, message, ansiSpans, 0)
+	s.pending.seq = s.nextSeq()
+	return true
+}
+
+// detach writes all the events in memory to the spill file and moves the newest event out of memory to pending, so
+// the newest events can be evicted while older events are paged in. Returns false if the events cannot be written
+func (lv *LogView) detach() bool {
+	s := lv.spill
+	if lv.lastEvent == nil {
+		s.detached = true
+		return true
+	}
+	last := findFirstWrappedLine(lv.lastEvent)
+	for event := lv.index.bySeq(s.nextSeq()); event != nil && event != last; event = lv.index.bySeq(s.nextSeq()) {
+		if err := s.write(event); err != nil {
+			lv.spillFailed(err)
+			return false
+		}
+	}
+	last = lv.mergeWrappedLines(last)
+	lv.deleteEvent(last, true)
+	last.previous = nil
+	last.next = nil
+	s.pending = last
+	s.detached = true
+	lv.following = false
+	return true
+}
+
+// attach appends the pending event to memory, the events in memory must end with the last event in the spill file
+func (lv *LogView) attach() {
+	s := lv.spill
+	pending := s.pending
+	s.pending = nil
+	s.detached = false
+	if pending == nil {
+		return
+	}
+	if lv.lastEvent == nil {
+		lv.index = newEventIndex(pending.seq)
+	}
+	lv.loadEvent(pending, false)
+	lv.ensureEventLimit()
+}
+
+// loadEvent adds the event paged in from the spill file before the first or after the last event in memory
+func (lv *LogView) loadEvent(event *logEventLine, first bool) {
+	event.filteredOut = !lv.matchesFilter(event)
+	if first {
+		lv.insertFirst(event)
+	} else {
+		lv.insertAfter(lv.lastEvent, event, true)
+	}
+	event.bookmarked = lv.isBookmarked(event.seq)
+	lv.processEvent(event)
+}
+
+// dropLastEvent removes the newest event from memory, it must be already written to the spill file
+func (lv *LogView) dropLastEvent() {
+	last := lv.mergeWrappedLines(findFirstWrappedLine(lv.lastEvent))
+	lv.deleteEvent(last, true)
+}
+
+func (lv *LogView) spillPageSize() int {
+	if lv.eventLimit == 0 {
+		return spillPageSize
+	}
+	return minInt(spillPageSize, maxInt(1, int(lv.eventLimit/2)))
+}
+
+// pageIn reads the page of events preceding the first event in memory or following the last one from the spill file.
+// Events on the other end are evicted to keep the event limit. Returns false if there are no more events to read
+func (lv *LogView) pageIn(backward bool) bool {
+	s := lv.spill
+	if s == nil || s.err != nil {
+		return false
+	}
+	if backward {
+		first := lv.index.firstSeq
+		if first <= s.baseSeq {
+			return false
+		}
+		count := minInt(lv.spillPageSize(), int(first-s.baseSeq))
+		for i := 1; i <= count; i++ {
+			event := lv.readSpilled(first - uint64(i))
+			if event == nil {
+				return i > 1
+			}
+			lv.loadEvent(event, true)
+		}
+		if !s.detached && lv.exceedsLimits(true) && !lv.detach() {
+			return true
+		}
+		for lv.exceedsLimits(false) {
+			lv.dropLastEvent()
+		}
+		return true
+	}
+
+	if !s.detached {
+		return false
+	}
+	next := lv.index.firstSeq + uint64(lv.index.count)
+	count := minInt(lv.spillPageSize(), int(s.nextSeq()-next))
+	for i := 0; i < count; i++ {
+		event := lv.readSpilled(next + uint64(i))
+		if event == nil {
+			return i > 0
+		}
+		lv.loadEvent(event, false)
+	}
+	if next+uint64(count) == s.nextSeq() {
+		lv.attach()
+	}
+	lv.ensureEventLimit()
+	return true
+}
+
+// showEvent returns the first line of the event with a given sequence number. If the event is not in memory, the
+// events around it are paged in from the spill file instead of the events in memory. Returns nil if there is no such
+// event
+func (lv *LogView) showEvent(seq uint64) *logEventLine {
+	if event := lv.index.bySeq(seq); event != nil {
+		return event
+	}
+	s := lv.spill
+	if s == nil || s.err != nil || seq < s.baseSeq || seq > lv.lastSeq() {
+		return nil
+	}
+	if lv.eventLimit == 0 && lv.maxMemory == 0 {
+		// all the events fit in memory
+		for lv.index.bySeq(seq) == nil && lv.pageIn(seq < lv.index.firstSeq) {
+		}
+		return lv.index.bySeq(seq)
+	}
+	if !s.detached && !lv.detach() {
+		return nil
+	}
+
+	size := uint64(lv.eventLimit)
+	if size == 0 {
+		size = 2 * spillPageSize
+	}
+	start := s.baseSeq
+	if seq-start > size/2 {
+		start = seq - size/2
+	}
+	end := start + size
+	reachesEnd := false
+	if end >= s.nextSeq() {
+		// pending event is appended to memory when the window reaches the end of the file, so the window leaves room
+		// for it. If the target doesn't fit together with the pending event, the window ends before the pending event
+		fileSize := size
+		if s.pending != nil {
+			fileSize--
+		}
+		end, start = s.nextSeq(), s.baseSeq
+		if end-start > fileSize {
+			start = end - fileSize
+		}
+		reachesEnd = start <= seq
+		if !reachesEnd {
+			start, end = seq, seq+size
+		}
+	}
+	lv.dropEvents(start)
+	for next := start; next < end; next++ {
+		event := lv.readSpilled(next)
+		if event == nil {
+			return lv.index.bySeq(seq)
+		}
+		lv.loadEvent(event, false)
+	}
+	// events after the target are evicted first if the memory budget is exceeded
+	trimmed := false
+	for lv.exceedsLimits(false) && lv.lastEvent.seq > seq {
+		lv.dropLastEvent()
+		trimmed = true
+	}
+	if reachesEnd && !trimmed {
+		lv.attach()
+	}
+	lv.ensureEventLimit()
+	return lv.index.bySeq(seq)
+}
+
+// spilledAtOrAfter returns the sequence number of the event that is not in memory, has the earliest timestamp not
+// before a given one and matches the filter, if it precedes the event found in memory
+func (lv *LogView) spilledAtOrAfter(timestamp time.Time, found *logEventLine) (uint64, bool) {
+	s := lv.spill
+	if s == nil || s.err != nil {
+		return 0, false
+	}
+	var best timestampEntry
+	hasBest := found != nil
+	if hasBest {
+		best = timestampEntry{timestamp: found.Timestamp, seq: found.seq}
+	}
+	var seq uint64
+	spilled := false
+	if p := s.pending; p != nil && !p.Timestamp.Before(timestamp) && lv.matchesFilter(p) {
+		if entry := (timestampEntry{timestamp: p.Timestamp, seq: p.seq}); !hasBest || entry.less(best) {
+			best, hasBest = entry, true
+			seq, spilled = p.seq, true
+		}
+	}
+
+	b, pos := s.timestamps.search(timestampEntry{timestamp: timestamp})
+	for ; b < len(s.timestamps.blocks); b, pos = b+1, 0 {
+		for _, entry := range s.timestamps.blocks[b][pos:] {
+			if hasBest && !entry.less(best) {
+				return seq, spilled
+			}
+			if lv.index.bySeq(entry.seq) != nil {
+				continue // events in memory were already checked
+			}
+			event := lv.readSpilled(entry.seq)
+			if event == nil {
+				return 0, false
+			}
+			if lv.matchesFilter(event) {
+				return entry.seq, true
+			}
+		}
+	}
+	return seq, spilled
+}
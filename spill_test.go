@@ -0,0 +1,278 @@
+package logview
+
+import (
+	"fmt"
+	"github.com/gdamore/tcell/v2"
+	"io"
+	"math/rand"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newSpillingLogView(t *testing.T, limit uint, count int) *LogView {
+	lv := NewLogView()
+	lv.pageHeight = 5
+	lv.pageWidth = 40
+	lv.SetEventLimit(limit)
+	if err := lv.SetSpillFile(filepath.Join(t.TempDir(), "spill")); err != nil {
+		t.Fatalf("Failed to open spill file: %v", err)
+	}
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < count; i++ {
+		event := NewLogEvent(fmt.Sprint(i), fmt.Sprintf("event %d", i))
+		event.Timestamp = start.Add(time.Duration(i) * time.Second)
+		lv.AppendEvent(event)
+	}
+	return lv
+}
+
+// checkSpillWindow verifies that the events in memory are contiguous, event IDs are the same as sequence numbers
+func checkSpillWindow(t *testing.T, lv *LogView) {
+	t.Helper()
+	seq := lv.index.firstSeq
+	for e := lv.firstEvent; e != nil; e = findLastWrappedLine(e).next {
+		if e.EventID != fmt.Sprint(seq) || e.seq != seq || lv.index.bySeq(seq) != e {
+			t.Fatalf("Invalid event %s with sequence number %d, expected %d", e.EventID, e.seq, seq)
+		}
+		seq++
+	}
+	if int(seq-lv.index.firstSeq) != lv.index.count || lv.eventCount != uint(lv.index.count) {
+		t.Fatalf("Invalid event count %d, index has %d", lv.eventCount, lv.index.count)
+	}
+}
+
+func TestSpill_EncodeDecode(t *testing.T) {
+	event := &logEventLine{
+		EventID:   "id",
+		Source:    "source",
+		Timestamp: time.Date(2022, 1, 1, 10, 0, 0, 123, time.FixedZone("EET", 2*60*60)),
+		Level:     LogLevelWarning,
+		Fields:    map[string]string{"host": "local", "pid": "42"},
+		Runes:     []rune("first ✓\nsecond"),
+		original:  "first\t✓\nsecond",
+		ansiSpans: []ansiSpan{{start: 1, end: 4, style: ansiStyle{fg: tcell.ColorRed, bg: tcell.ColorDefault, attrs: tcell.AttrBold}}},
+	}
+	decoded, err := decodeEvent(encodeEvent(nil, event))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if decoded.EventID != "id" || decoded.Source != "source" ||
+		decoded.Timestamp.Format(time.RFC3339Nano) != "2022-01-01T10:00:00.000000123+02:00" || decoded.Level != LogLevelWarning ||
+		string(decoded.Runes) != "first ✓\nsecond" || !decoded.hasNewLines || decoded.original != event.original {
+		t.Errorf("Invalid decoded event: %+v", decoded)
+	}
+	if !reflect.DeepEqual(decoded.Fields, event.Fields) || !reflect.DeepEqual(decoded.ansiSpans, event.ansiSpans) {
+		t.Errorf("Invalid decoded fields or spans: %v %v", decoded.Fields, decoded.ansiSpans)
+	}
+
+	event.Timestamp = time.Time{}
+	if decoded, err = decodeEvent(encodeEvent(nil, event)); err != nil || !decoded.Timestamp.IsZero() {
+		t.Errorf("Zero timestamp must stay zero: %v, err=%v", decoded.Timestamp, err)
+	}
+
+	if _, err = decodeEvent(encodeEvent(nil, event)[:10]); err == nil {
+		t.Errorf("Expected error for truncated record")
+	}
+}
+
+func TestSpill_ReadRecords(t *testing.T) {
+	const count = 3*spillIndexStep + 5
+	s, err := openSpillStore(filepath.Join(t.TempDir(), "spill"), 10)
+	if err != nil {
+		t.Fatalf("Failed to open spill file: %v", err)
+	}
+	defer s.close()
+	for i := 0; i < count; i++ {
+		event := newEventLine(NewLogEvent(fmt.Sprint(i), strings.Repeat("x", i)), []rune(strings.Repeat("x", i)), nil, 0)
+		event.seq = uint64(10 + i)
+		if err = s.write(event); err != nil {
+			t.Fatalf("Failed to write event: %v", err)
+		}
+	}
+	if s.count != count || len(s.offsets) != 4 {
+		t.Fatalf("Invalid record count %d, %d offsets", s.count, len(s.offsets))
+	}
+
+	order := rand.New(rand.NewSource(1)).Perm(count)
+	for i := count - 1; i >= 0; i-- {
+		order = append(order, i)
+	}
+	for i := 0; i < count; i++ {
+		order = append(order, i)
+	}
+	for _, i := range order {
+		event, err := s.read(uint64(10 + i))
+		if err != nil || event.EventID != fmt.Sprint(i) || len(event.Runes) != i || event.seq != uint64(10+i) {
+			t.Fatalf("Invalid event %d: %+v, err=%v", i, event, err)
+		}
+	}
+	if _, err = s.read(10 + count); err != io.EOF {
+		t.Errorf("Expected EOF after the last record, got %v", err)
+	}
+}
+
+func TestLogView_SpillScrolling(t *testing.T) {
+	lv := newSpillingLogView(t, 10, 100)
+	if lv.GetEventCount() != 10 || lv.GetSpilledEventCount() != 90 {
+		t.Fatalf("Unexpected event counts: %d in memory, %d spilled", lv.GetEventCount(), lv.GetSpilledEventCount())
+	}
+
+	lv.ScrollToTop()
+	if lv.current.EventID != "0" || lv.firstEvent.EventID != "0" || lv.GetEventCount() != 10 {
+		t.Errorf("First event must be paged in, current %s, %d events in memory", lv.current.EventID, lv.GetEventCount())
+	}
+
+	// newest events are written to disk while older events are displayed
+	lv.AppendEvent(NewLogEvent("100", "event 100"))
+	if lv.GetEventCount() != 10 || lv.current.EventID != "0" {
+		t.Errorf("Appended event must not be added to memory")
+	}
+
+	for i := 0; i < 100; i++ {
+		lv.SelectNextEvent()
+		if lv.GetEventCount() > 10 {
+			t.Fatalf("Event limit exceeded: %d", lv.GetEventCount())
+		}
+	}
+	if lv.current.EventID != "100" || lv.lastEvent.EventID != "100" {
+		t.Errorf("Scrolling must reach the newest event, current %s", lv.current.EventID)
+	}
+	for i := 99; i >= 50; i-- {
+		lv.SelectPrevEvent()
+		if lv.current.EventID != fmt.Sprint(i) {
+			t.Fatalf("Invalid event after scrolling up: %s, expected %d", lv.current.EventID, i)
+		}
+	}
+
+	checkSpillWindow(t, lv)
+	for i := 0; i < 30 && lv.current.EventID != "0"; i++ {
+		lv.ScrollPageUp()
+		checkSpillWindow(t, lv)
+	}
+	if lv.current.EventID != "0" {
+		t.Errorf("Scrolling must reach the first event, current %s", lv.current.EventID)
+	}
+
+	lv.ScrollToBottom()
+	checkSpillWindow(t, lv)
+	if lv.current.EventID != "100" || !lv.IsFollowing() || lv.isDetached() {
+		t.Errorf("Log view must be attached to the newest event, current %s", lv.current.EventID)
+	}
+	lv.AppendEvent(NewLogEvent("101", "event 101"))
+	if lv.lastEvent.EventID != "101" || lv.GetEventCount() != 10 {
+		t.Errorf("Appended event must be added to memory")
+	}
+}
+
+func TestLogView_SpillConcatenation(t *testing.T) {
+	lv := newSpillingLogView(t, 5, 20)
+	lv.SetConcatenateEvents(true)
+	lv.ScrollToTop()
+	lv.AppendEvent(NewLogEvent("20", "event 20"))
+	lv.AppendEvent(NewLogEvent("21", "  continued"))
+
+	lv.ScrollToBottom()
+	if last := lv.lastEvent.AsLogEvent(); last.EventID != "20" || last.Message != "event 20\n  continued" {
+		t.Errorf("Events appended while detached must be concatenated: %+v", last)
+	}
+}
+
+func TestLogView_SpillPendingCopy(t *testing.T) {
+	lv := newSpillingLogView(t, 5, 20)
+	lv.SetConcatenateEvents(true)
+	lv.ScrollToTop()
+	lv.AppendEvent(NewLogEvent("20", "event 20"))
+
+	pending := lv.historyEvent(lv.spill.pending.seq)
+	if pending == lv.spill.pending || pending.previous != nil || pending.next != nil {
+		t.Fatalf("Pending event must be copied")
+	}
+	lv.AppendEvent(NewLogEvent("21", "  continued"))
+	if string(pending.Runes) != "event 20" || string(lv.spill.pending.Runes) != "event 20\n  continued" {
+		t.Errorf("Copy must not change after concatenation: %q", string(pending.Runes))
+	}
+}
+
+func TestLogView_SpillSearch(t *testing.T) {
+	lv := newSpillingLogView(t, 10, 100)
+	lv.SetSearchText("event 3")
+
+	if !lv.SearchNext() || lv.current.EventID != "3" {
+		t.Fatalf("Search must find the event on disk, current %s", lv.current.EventID)
+	}
+	checkSpillWindow(t, lv)
+	if !lv.SearchNext() || lv.current.EventID != "30" {
+		t.Errorf("Invalid next match %s", lv.current.EventID)
+	}
+	if !lv.SearchPrev() || !lv.SearchPrev() || lv.current.EventID != "39" {
+		t.Errorf("Search must wrap around, current %s", lv.current.EventID)
+	}
+
+	lv.SetSearchText("missing")
+	if lv.SearchNext() {
+		t.Errorf("Expected no matches")
+	}
+}
+
+func TestLogView_SpillScrollToTimestamp(t *testing.T) {
+	lv := newSpillingLogView(t, 10, 100)
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	if !lv.ScrollToTimestamp(start.Add(5500*time.Millisecond)) || lv.current.EventID != "6" {
+		t.Errorf("Expected event 6 on disk, current %s", lv.current.EventID)
+	}
+	if !lv.ScrollToTimestamp(start.Add(95*time.Second)) || lv.current.EventID != "95" {
+		t.Errorf("Expected event 95 in memory, current %s", lv.current.EventID)
+	}
+
+	lv.SetFilterRegex("event [0-9]$")
+	if !lv.ScrollToTimestamp(start) || lv.current.EventID != "0" {
+		t.Errorf("Expected event 0, current %s", lv.current.EventID)
+	}
+	if lv.ScrollToTimestamp(start.Add(10 * time.Second)) {
+		t.Errorf("Events hidden by the filter must be skipped, current %s", lv.current.EventID)
+	}
+}
+
+func TestLogView_SpillOldestEvent(t *testing.T) {
+	// one event is spilled and the window reaching the end of the file includes the pending event
+	for limit := uint(1); limit <= 8; limit++ {
+		lv := newSpillingLogView(t, limit, int(limit)+1)
+		start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+		if !lv.ScrollToTimestamp(start) || lv.current.EventID != "0" {
+			t.Errorf("Limit %d: expected the oldest spilled event, current %s", limit, lv.current.EventID)
+		}
+		checkSpillWindow(t, lv)
+
+		lv = newSpillingLogView(t, limit, int(limit)+1)
+		lv.ScrollToBottom()
+		lv.SetSearchText("event 0")
+		if !lv.SearchPrev() || lv.current.EventID != "0" {
+			t.Errorf("Limit %d: search must find the oldest spilled event, current %s", limit, lv.current.EventID)
+		}
+		if lv.GetEventCount() > limit {
+			t.Errorf("Limit %d: %d events in memory", limit, lv.GetEventCount())
+		}
+	}
+}
+
+func TestLogView_SpillClear(t *testing.T) {
+	lv := newSpillingLogView(t, 10, 100)
+	lv.ScrollToTop()
+	lv.Clear()
+	if lv.GetSpilledEventCount() != 0 || lv.isDetached() || lv.GetSpillError() != nil {
+		t.Errorf("Spill file must be truncated")
+	}
+	lv.AppendEvent(NewLogEvent("new", "new event"))
+	lv.ScrollToTop()
+	if lv.current.EventID != "new" {
+		t.Errorf("Unexpected current event %s", lv.current.EventID)
+	}
+
+	if err := lv.SetSpillFile(""); err != nil || lv.spill != nil {
+		t.Errorf("Spill file must be closed: %v", err)
+	}
+}
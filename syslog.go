@@ -0,0 +1,210 @@
+package logview
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SyslogParser parses syslog messages in RFC 3164 (BSD) and RFC 5424 formats. Format is detected for each line.
+//
+// Event level is derived from the message priority, event source is the application name (tag) and the timestamp is
+// taken from the message header. RFC 3164 timestamps do not have a year, current year is assumed.
+//
+// Host name, process id and message id, when present, are stored in event fields "host", "pid" and "msgid".
+type SyslogParser struct {
+	parserBase
+}
+
+// NewSyslogParser creates a syslog message parser
+func NewSyslogParser() *SyslogParser {
+	return &SyslogParser{}
+}
+
+// syslogMessage contains parts of the syslog message common for both RFC 3164 and RFC 5424
+type syslogMessage struct {
+	severity  int
+	timestamp time.Time
+	hostname  string
+	appName   string
+	procID    string
+	msgID     string
+	message   string
+}
+
+// Parse converts a line of text into an event
+func (p *SyslogParser) Parse(line string) (*LogEvent, error) {
+	event := p.newEvent(line)
+	priority, rest, err := parseSyslogPriority(line)
+	if err != nil {
+		return event, err
+	}
+	var msg *syslogMessage
+	if strings.HasPrefix(rest, "1 ") {
+		msg, err = p.parseRFC5424(rest[2:])
+	} else {
+		msg, err = p.parseRFC3164(rest)
+	}
+	if err != nil {
+		return event, err
+	}
+	msg.severity = priority & 0x07
+
+	event.Message = msg.message
+	event.Level = syslogLevels[msg.severity]
+	if !msg.timestamp.IsZero() {
+		event.Timestamp = msg.timestamp
+	}
+	if msg.appName != "" {
+		event.Source = msg.appName
+	} else {
+		event.Source = msg.hostname
+	}
+	for name, value := range map[string]string{"host": msg.hostname, "pid": msg.procID, "msgid": msg.msgID} {
+		if value != "" {
+			if event.Fields == nil {
+				event.Fields = make(map[string]string)
+			}
+			event.Fields[name] = value
+		}
+	}
+	return event, nil
+}
+
+// syslogLevels maps syslog severity to log level
+var syslogLevels = []LogLevel{
+	LogLevelFatal,    // emergency
+	LogLevelCritical, // alert
+	LogLevelCritical, // critical
+	LogLevelError,    // error
+	LogLevelWarning,  // warning
+	LogLevelNotice,   // notice
+	LogLevelInfo,     // informational
+	LogLevelDebug,    // debug
+}
+
+// parseSyslogPriority parses <PRI> part of the message, returns the priority value and the remainder of the line
+func parseSyslogPriority(line string) (int, string, error) {
+	if !strings.HasPrefix(line, "<") {
+		return 0, line, fmt.Errorf("%w: missing priority", ErrUnrecognizedLine)
+	}
+	end := strings.IndexByte(line, '>')
+	if end < 2 || end > 4 {
+		return 0, line, fmt.Errorf("%w: invalid priority", ErrUnrecognizedLine)
+	}
+	priority, err := strconv.Atoi(line[1:end])
+	if err != nil || priority > 191 {
+		return 0, line, fmt.Errorf("%w: invalid priority", ErrUnrecognizedLine)
+	}
+	return priority, line[end+1:], nil
+}
+
+// parseRFC3164 parses "Mmm dd hh:mm:ss HOSTNAME TAG[PID]: MESSAGE"
+func (p *SyslogParser) parseRFC3164(rest string) (*syslogMessage, error) {
+	msg := &syslogMessage{}
+	if len(rest) < len(time.Stamp) {
+		return nil, fmt.Errorf("%w: message is too short", ErrUnrecognizedLine)
+	}
+	location := p.location
+	if location == nil {
+		location = time.Local
+	}
+	ts, err := time.ParseInLocation(time.Stamp, rest[:len(time.Stamp)], location)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timestamp %q: %w", rest[:len(time.Stamp)], err)
+	}
+	now := time.Now().In(location)
+	msg.timestamp = ts.AddDate(now.Year(), 0, 0)
+	if msg.timestamp.After(now.AddDate(0, 0, 1)) { // message from the last year
+		msg.timestamp = msg.timestamp.AddDate(-1, 0, 0)
+	}
+	rest = strings.TrimPrefix(rest[len(time.Stamp):], " ")
+
+	msg.hostname, rest = splitSyslogToken(rest)
+
+	// tag is terminated by ':', '[' or space and must not be longer than 32 characters
+	tagEnd := strings.IndexAny(rest, ":[ ")
+	if tagEnd <= 0 || tagEnd > 32 || rest[tagEnd] == ' ' {
+		msg.message = rest
+		return msg, nil
+	}
+	msg.appName = rest[:tagEnd]
+	rest = rest[tagEnd:]
+	if strings.HasPrefix(rest, "[") {
+		if pidEnd := strings.IndexByte(rest, ']'); pidEnd > 0 {
+			msg.procID = rest[1:pidEnd]
+			rest = rest[pidEnd+1:]
+		}
+	}
+	msg.message = strings.TrimPrefix(strings.TrimPrefix(rest, ":"), " ")
+	return msg, nil
+}
+
+// parseRFC5424 parses "TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA MSG"
+func (p *SyslogParser) parseRFC5424(rest string) (*syslogMessage, error) {
+	msg := &syslogMessage{}
+	var ts string
+	ts, rest = splitSyslogToken(rest)
+	if ts != "" {
+		timestamp, err := p.parseTimestamp(ts)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timestamp %q: %w", ts, err)
+		}
+		msg.timestamp = timestamp
+	}
+	msg.hostname, rest = splitSyslogToken(rest)
+	msg.appName, rest = splitSyslogToken(rest)
+	msg.procID, rest = splitSyslogToken(rest)
+	msg.msgID, rest = splitSyslogToken(rest)
+
+	rest, err := skipStructuredData(rest)
+	if err != nil {
+		return nil, err
+	}
+	msg.message = strings.TrimPrefix(strings.TrimPrefix(rest, " "), "\ufeff")
+	return msg, nil
+}
+
+// splitSyslogToken returns the header token up to the next space and the remainder of the line.
+// NILVALUE "-" is returned as an empty string
+func splitSyslogToken(rest string) (string, string) {
+	token := rest
+	end := strings.IndexByte(rest, ' ')
+	if end >= 0 {
+		token = rest[:end]
+		rest = rest[end+1:]
+	} else {
+		rest = ""
+	}
+	if token == "-" {
+		token = ""
+	}
+	return token, rest
+}
+
+// skipStructuredData skips the structured data elements and returns the remainder of the message
+func skipStructuredData(rest string) (string, error) {
+	if strings.HasPrefix(rest, "-") {
+		return rest[1:], nil
+	}
+	for strings.HasPrefix(rest, "[") {
+		inQuotes := false
+		end := -1
+		for i := 1; i < len(rest) && end < 0; i++ {
+			switch {
+			case rest[i] == '\\' && inQuotes:
+				i++
+			case rest[i] == '"':
+				inQuotes = !inQuotes
+			case rest[i] == ']' && !inQuotes:
+				end = i
+			}
+		}
+		if end < 0 {
+			return rest, fmt.Errorf("%w: unterminated structured data", ErrUnrecognizedLine)
+		}
+		rest = rest[end+1:]
+	}
+	return rest, nil
+}
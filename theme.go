@@ -0,0 +1,494 @@
+package logview
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/BurntSushi/toml"
+	"github.com/gdamore/tcell/v2"
+	"gopkg.in/yaml.v3"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Theme is a named set of styles for LogView and LogVelocityView.
+//
+// Styles are written in the same form as the highlight group names: foreground_background__attributes, where colors
+// are web color names, hex RGB values (#rrggbb or xrrggbb) or palette indices (colorN), i.e. "white_black__bold" or
+// "#ff8800". Styles that are not set in the theme are left unchanged when the theme is applied.
+type Theme struct {
+	Name string `json:"name" yaml:"name" toml:"name"`
+	// Text is the default style of the log messages
+	Text string `json:"text,omitempty" yaml:"text,omitempty" toml:"text,omitempty"`
+	// Source is the style of the event source column
+	Source string `json:"source,omitempty" yaml:"source,omitempty" toml:"source,omitempty"`
+	// Timestamp is the style of the event timestamp column
+	Timestamp string `json:"timestamp,omitempty" yaml:"timestamp,omitempty" toml:"timestamp,omitempty"`
+	// CurrentBackground is the background color of the current event
+	CurrentBackground string `json:"currentBackground,omitempty" yaml:"currentBackground,omitempty" toml:"currentBackground,omitempty"`
+	// SelectionBackground is the background color of the selected events
+	SelectionBackground string `json:"selectionBackground,omitempty" yaml:"selectionBackground,omitempty" toml:"selectionBackground,omitempty"`
+	// Bookmark is the color of the bookmark and mark markers in the gutter
+	Bookmark string `json:"bookmark,omitempty" yaml:"bookmark,omitempty" toml:"bookmark,omitempty"`
+	// Search is the style of the search matches
+	Search string `json:"search,omitempty" yaml:"search,omitempty" toml:"search,omitempty"`
+	// Levels maps log level names to the foreground and background colors of the events of that level
+	Levels map[string]string `json:"levels,omitempty" yaml:"levels,omitempty" toml:"levels,omitempty"`
+	// Fields maps event field names to the styles of their header columns
+	Fields map[string]string `json:"fields,omitempty" yaml:"fields,omitempty" toml:"fields,omitempty"`
+	// Highlights maps highlight group names to styles, see LogView.RegisterHighlightStyle
+	Highlights map[string]string `json:"highlights,omitempty" yaml:"highlights,omitempty" toml:"highlights,omitempty"`
+	// Velocity contains styles of LogVelocityView
+	Velocity VelocityTheme `json:"velocity" yaml:"velocity" toml:"velocity"`
+}
+
+// VelocityTheme is a set of styles for LogVelocityView
+type VelocityTheme struct {
+	// Text is the style of the axes and the default color of the bars
+	Text string `json:"text,omitempty" yaml:"text,omitempty" toml:"text,omitempty"`
+	// Levels maps log level names to the bar colors used when the view displays events of that level
+	Levels map[string]string `json:"levels,omitempty" yaml:"levels,omitempty" toml:"levels,omitempty"`
+}
+
+// ThemeFormat is the format of the theme file
+type ThemeFormat int
+
+const (
+	ThemeJSON ThemeFormat = iota
+	ThemeYAML
+	ThemeTOML
+)
+
+// Names of the built-in themes
+const (
+	ThemeDark         = "dark"
+	ThemeLight        = "light"
+	ThemeSolarized    = "solarized"
+	ThemeHighContrast = "high-contrast"
+)
+
+var builtinThemes = map[string]func() *Theme{
+	ThemeDark:         NewDarkTheme,
+	ThemeLight:        NewLightTheme,
+	ThemeSolarized:    NewSolarizedTheme,
+	ThemeHighContrast: NewHighContrastTheme,
+}
+
+// NewDarkTheme returns the theme with the default LogView styles
+func NewDarkTheme() *Theme {
+	return &Theme{
+		Name:                ThemeDark,
+		Text:                "white_black",
+		Source:              "darkgoldenrod",
+		Timestamp:           "darkorange",
+		CurrentBackground:   "dimgray",
+		SelectionBackground: "darkslateblue",
+		Bookmark:            "aqua",
+		Search:              "black_gold",
+		Levels: map[string]string{
+			"trace":    "gray",
+			"debug":    "silver",
+			"info":     "",
+			"notice":   "lightskyblue",
+			"warning":  "_saddlebrown",
+			"error":    "_indianred",
+			"critical": "white_firebrick",
+			"fatal":    "yellow_darkred",
+		},
+		Velocity: VelocityTheme{
+			Text: "white_color239",
+			Levels: map[string]string{
+				"trace":    "gray",
+				"debug":    "silver",
+				"info":     "",
+				"notice":   "lightskyblue",
+				"warning":  "saddlebrown",
+				"error":    "indianred",
+				"critical": "firebrick",
+				"fatal":    "darkred",
+			},
+		},
+	}
+}
+
+// NewLightTheme returns the theme for terminals with light background
+func NewLightTheme() *Theme {
+	return &Theme{
+		Name:                ThemeLight,
+		Text:                "black_white",
+		Source:              "saddlebrown",
+		Timestamp:           "#af5f00",
+		CurrentBackground:   "lightgray",
+		SelectionBackground: "lightsteelblue",
+		Bookmark:            "teal",
+		Search:              "black_yellow",
+		Levels: map[string]string{
+			"trace":    "gray",
+			"debug":    "dimgray",
+			"info":     "",
+			"notice":   "steelblue",
+			"warning":  "_moccasin",
+			"error":    "_lightpink",
+			"critical": "white_crimson",
+			"fatal":    "yellow_darkred",
+		},
+		Velocity: VelocityTheme{
+			Text: "black_gainsboro",
+			Levels: map[string]string{
+				"trace":    "gray",
+				"debug":    "dimgray",
+				"info":     "",
+				"notice":   "steelblue",
+				"warning":  "darkorange",
+				"error":    "crimson",
+				"critical": "firebrick",
+				"fatal":    "darkred",
+			},
+		},
+	}
+}
+
+// NewSolarizedTheme returns the theme using the dark Solarized palette
+func NewSolarizedTheme() *Theme {
+	return &Theme{
+		Name:                ThemeSolarized,
+		Text:                "#839496_#002b36",
+		Source:              "#b58900",
+		Timestamp:           "#cb4b16",
+		CurrentBackground:   "#073642",
+		SelectionBackground: "#1b4f5c",
+		Bookmark:            "#2aa198",
+		Search:              "#002b36_#b58900",
+		Levels: map[string]string{
+			"trace":    "#586e75",
+			"debug":    "#93a1a1",
+			"info":     "",
+			"notice":   "#268bd2",
+			"warning":  "#002b36_#b58900",
+			"error":    "#fdf6e3_#dc322f",
+			"critical": "#fdf6e3_#d33682",
+			"fatal":    "#fdf6e3_#6c71c4",
+		},
+		Velocity: VelocityTheme{
+			Text: "#93a1a1_#073642",
+			Levels: map[string]string{
+				"trace":    "#586e75",
+				"debug":    "#93a1a1",
+				"info":     "",
+				"notice":   "#268bd2",
+				"warning":  "#b58900",
+				"error":    "#dc322f",
+				"critical": "#d33682",
+				"fatal":    "#6c71c4",
+			},
+		},
+	}
+}
+
+// NewHighContrastTheme returns the theme using only the basic bright colors
+func NewHighContrastTheme() *Theme {
+	return &Theme{
+		Name:                ThemeHighContrast,
+		Text:                "white_black",
+		Source:              "yellow",
+		Timestamp:           "aqua",
+		CurrentBackground:   "blue",
+		SelectionBackground: "navy",
+		Bookmark:            "lime",
+		Search:              "black_yellow__bold",
+		Levels: map[string]string{
+			"trace":    "silver",
+			"debug":    "white",
+			"info":     "",
+			"notice":   "aqua",
+			"warning":  "black_yellow",
+			"error":    "white_red",
+			"critical": "yellow_red",
+			"fatal":    "yellow_purple",
+		},
+		Velocity: VelocityTheme{
+			Text: "white_black",
+			Levels: map[string]string{
+				"trace":    "silver",
+				"debug":    "white",
+				"info":     "",
+				"notice":   "aqua",
+				"warning":  "yellow",
+				"error":    "red",
+				"critical": "fuchsia",
+				"fatal":    "purple",
+			},
+		},
+	}
+}
+
+// BuiltinThemes returns the names of all the built-in themes
+func BuiltinThemes() []string {
+	names := make([]string, 0, len(builtinThemes))
+	for name := range builtinThemes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetBuiltinTheme returns a copy of the built-in theme with a given name or nil if there is no such theme
+func GetBuiltinTheme(name string) *Theme {
+	if newTheme, ok := builtinThemes[strings.ToLower(name)]; ok {
+		return newTheme()
+	}
+	return nil
+}
+
+// LoadTheme reads the theme from a file. Format of the file is determined by its extension: .json, .yaml, .yml
+// or .toml
+func LoadTheme(path string) (*Theme, error) {
+	format, err := themeFormatOf(path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadTheme(f, format)
+}
+
+// ReadTheme reads the theme in a given format and checks that all its styles are valid
+func ReadTheme(r io.Reader, format ThemeFormat) (*Theme, error) {
+	theme := &Theme{}
+	var err error
+	switch format {
+	case ThemeJSON:
+		err = json.NewDecoder(r).Decode(theme)
+	case ThemeYAML:
+		err = yaml.NewDecoder(r).Decode(theme)
+	case ThemeTOML:
+		_, err = toml.NewDecoder(r).Decode(theme)
+	default:
+		err = fmt.Errorf("unknown theme format %d", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = theme.Validate(); err != nil {
+		return nil, err
+	}
+	return theme, nil
+}
+
+// Save writes the theme to a file. Format of the file is determined by its extension: .json, .yaml, .yml or .toml
+func (t *Theme) Save(path string) error {
+	format, err := themeFormatOf(path)
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err = t.Write(f, format); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Write writes the theme in a given format
+func (t *Theme) Write(w io.Writer, format ThemeFormat) error {
+	switch format {
+	case ThemeJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(t)
+	case ThemeYAML:
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(t); err != nil {
+			return err
+		}
+		return encoder.Close()
+	case ThemeTOML:
+		return toml.NewEncoder(w).Encode(t)
+	}
+	return fmt.Errorf("unknown theme format %d", format)
+}
+
+// Validate checks that all the styles, colors and log level names of the theme are valid
+func (t *Theme) Validate() error {
+	styles := map[string]string{
+		"text":          t.Text,
+		"source":        t.Source,
+		"timestamp":     t.Timestamp,
+		"search":        t.Search,
+		"velocity text": t.Velocity.Text,
+	}
+	for name, spec := range t.Fields {
+		styles["field "+name] = spec
+	}
+	for name, spec := range t.Highlights {
+		styles["highlight "+name] = spec
+	}
+	for name, spec := range styles {
+		if err := validateStyleName(spec); err != nil {
+			return fmt.Errorf("%s style: %w", name, err)
+		}
+	}
+	colors := map[string]string{
+		"current background":   t.CurrentBackground,
+		"selection background": t.SelectionBackground,
+		"bookmark":             t.Bookmark,
+	}
+	for name, color := range colors {
+		if err := validateColorName(color); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	for name, spec := range t.Levels {
+		if _, err := ParseLogLevel(name); err != nil {
+			return err
+		}
+		if err := validateStyleName(spec); err != nil {
+			return fmt.Errorf("%s level style: %w", name, err)
+		}
+	}
+	for name, color := range t.Velocity.Levels {
+		if _, err := ParseLogLevel(name); err != nil {
+			return err
+		}
+		if err := validateColorName(color); err != nil {
+			return fmt.Errorf("%s level velocity color: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// ApplyTheme sets all the styles defined in the theme and recalculates highlighting of all the events in the log
+// view the same way RefreshHighlights does, so there is no need to call it. Invalid styles and colors are ignored,
+// use Theme.Validate to check the theme.
+//
+// Styles of the header columns are based on the text style of the theme, so the columns keep the background color
+// of the log messages
+func (lv *LogView) ApplyTheme(theme *Theme) {
+	lv.Lock()
+	defer lv.Unlock()
+
+	if theme.Text != "" {
+		lv.defaultStyle = themeStyle(theme.Text, tcell.StyleDefault)
+	}
+	columnStyles := map[string]string{
+		ColumnSource:    theme.Source,
+		ColumnTimestamp: theme.Timestamp,
+	}
+	for name, spec := range theme.Fields {
+		if c := lv.findColumn(name); c != nil && c.kind == columnField {
+			columnStyles[name] = spec
+		}
+	}
+	for id, spec := range columnStyles {
+		if c := lv.findColumn(id); c != nil && spec != "" {
+			c.style = themeStyle(spec, lv.defaultStyle)
+		}
+	}
+	setThemeColor(theme.CurrentBackground, &lv.currentBgColor)
+	setThemeColor(theme.SelectionBackground, &lv.selectionBgColor)
+	setThemeColor(theme.Bookmark, &lv.bookmarkColor)
+	if theme.Search != "" {
+		lv.searchStyle = themeStyle(theme.Search, tcell.StyleDefault)
+	}
+	for name, spec := range theme.Levels {
+		if level, err := ParseLogLevel(name); err == nil {
+			fg, bg, _ := themeStyle(spec, tcell.StyleDefault).Decompose()
+			lv.levelColors[level] = levelColors{fg: fg, bg: bg}
+		}
+	}
+	for name, spec := range theme.Highlights {
+		lv.highlightStyles[strings.ToLower(name)] = themeStyle(spec, lv.defaultStyle)
+	}
+
+	lv.invalidateHighlights()
+}
+
+// ApplyTheme sets the styles of the velocity view defined in the theme
+func (lh *LogVelocityView) ApplyTheme(theme *Theme) {
+	lh.Lock()
+	defer lh.Unlock()
+
+	if theme.Velocity.Text != "" {
+		lh.defaultStyle = themeStyle(theme.Velocity.Text, tcell.StyleDefault)
+	}
+	for name, color := range theme.Velocity.Levels {
+		if level, err := ParseLogLevel(name); err == nil {
+			if c, ok := parseColorName(strings.ToLower(color)); ok || color == "" {
+				lh.levelColors[level] = c
+			}
+		}
+	}
+}
+
+// *******************************
+// internal implementation details
+
+func themeFormatOf(path string) (ThemeFormat, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return ThemeJSON, nil
+	case ".yaml", ".yml":
+		return ThemeYAML, nil
+	case ".toml":
+		return ThemeTOML, nil
+	}
+	return ThemeJSON, fmt.Errorf("unknown theme file format %q", filepath.Ext(path))
+}
+
+func themeStyle(spec string, base tcell.Style) tcell.Style {
+	return parseStyleName(strings.ToLower(spec), base)
+}
+
+// validateStyleName checks that all the colors and attributes in the style name are known
+func validateStyleName(spec string) error {
+	colors, attrs := strings.ToLower(spec), ""
+	if i := strings.Index(colors, "__"); i >= 0 {
+		colors, attrs = colors[:i], colors[i+2:]
+	}
+	colorPair := strings.Split(colors, "_")
+	if len(colorPair) > 2 {
+		return fmt.Errorf("invalid style %q", spec)
+	}
+	for _, color := range colorPair {
+		if err := validateColorName(color); err != nil {
+			return err
+		}
+	}
+	if attrs != "" {
+		for _, attr := range strings.Split(attrs, "_") {
+			if _, ok := styleAttributes[attr]; !ok {
+				return fmt.Errorf("unknown attribute %q", attr)
+			}
+		}
+	}
+	return nil
+}
+
+// setThemeColor sets the color if it is defined in the theme and valid
+func setThemeColor(spec string, color *tcell.Color) {
+	if spec == "" {
+		return
+	}
+	if c, ok := parseColorName(strings.ToLower(spec)); ok {
+		*color = c
+	}
+}
+
+func validateColorName(color string) error {
+	if color == "" {
+		return nil
+	}
+	if _, ok := parseColorName(strings.ToLower(color)); !ok {
+		return fmt.Errorf("unknown color %q", color)
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package logview
+
+import (
+	"bytes"
+	"github.com/gdamore/tcell/v2"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuiltinThemes(t *testing.T) {
+	names := BuiltinThemes()
+	if !reflect.DeepEqual(names, []string{ThemeDark, ThemeHighContrast, ThemeLight, ThemeSolarized}) {
+		t.Errorf("Unexpected built-in themes: %v", names)
+	}
+	for _, name := range names {
+		theme := GetBuiltinTheme(name)
+		if theme == nil || theme.Name != name {
+			t.Fatalf("Built-in theme %s not found", name)
+		}
+		if err := theme.Validate(); err != nil {
+			t.Errorf("Built-in theme %s is invalid: %v", name, err)
+		}
+	}
+	if GetBuiltinTheme("missing") != nil {
+		t.Errorf("Expected nil for unknown theme")
+	}
+}
+
+func TestTheme_SaveLoad(t *testing.T) {
+	dir := t.TempDir()
+	theme := NewSolarizedTheme()
+	theme.Highlights = map[string]string{"number": "#d33682__bold"}
+	theme.Fields = map[string]string{"host": "#2aa198"}
+
+	for _, name := range []string{"theme.json", "theme.yaml", "theme.yml", "theme.toml"} {
+		path := filepath.Join(dir, name)
+		if err := theme.Save(path); err != nil {
+			t.Fatalf("Failed to save %s: %v", name, err)
+		}
+		loaded, err := LoadTheme(path)
+		if err != nil {
+			t.Fatalf("Failed to load %s: %v", name, err)
+		}
+		if !reflect.DeepEqual(loaded, theme) {
+			t.Errorf("Theme loaded from %s differs: %+v", name, loaded)
+		}
+	}
+
+	if err := theme.Save(filepath.Join(dir, "theme.ini")); err == nil {
+		t.Errorf("Expected error for unknown file format")
+	}
+}
+
+func TestTheme_Validate(t *testing.T) {
+	invalid := []string{
+		`{"text": "nocolor"}`,
+		`{"search": "red_blue__shiny"}`,
+		`{"currentBackground": "red_blue"}`,
+		`{"selectionBackground": "nocolor"}`,
+		`{"bookmark": "red__bold"}`,
+		`{"levels": {"loud": "red"}}`,
+		`{"velocity": {"levels": {"error": "red__bold"}}}`,
+	}
+	for _, text := range invalid {
+		if _, err := ReadTheme(strings.NewReader(text), ThemeJSON); err == nil {
+			t.Errorf("Expected error for %s", text)
+		}
+	}
+	theme, err := ReadTheme(strings.NewReader("text = \"#FFFFFF_color17__Bold\"\n[levels]\nwarn = \"_Orange\"\n"), ThemeTOML)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var buf bytes.Buffer
+	if err = theme.Write(&buf, ThemeYAML); err != nil || !strings.Contains(buf.String(), "warn: _Orange") {
+		t.Errorf("Unexpected YAML: %s, %v", buf.String(), err)
+	}
+}
+
+func TestLogView_ApplyTheme(t *testing.T) {
+	lv := NewLogView()
+	lv.SetLevelHighlighting(true)
+	lv.AddFieldColumn("host", 8, tcell.StyleDefault)
+	lv.AppendEvent(NewLogEvent("1", "message"))
+	warning := NewLogEvent("2", "warning")
+	warning.Level = LogLevelWarning
+	lv.AppendEvent(warning)
+
+	theme := NewLightTheme()
+	theme.Fields = map[string]string{"host": "navy", "missing": "red"}
+	lv.ApplyTheme(theme)
+
+	fg, bg, _ := lv.defaultStyle.Decompose()
+	if fg != tcell.ColorBlack || bg != tcell.ColorWhite {
+		t.Errorf("Unexpected text colors: %v %v", fg, bg)
+	}
+	if fg, bg, _ = lv.findColumn("host").style.Decompose(); fg != tcell.ColorNavy || bg != tcell.ColorWhite {
+		t.Errorf("Unexpected field column colors: %v %v", fg, bg)
+	}
+	if lv.findColumn("missing") != nil {
+		t.Errorf("Theme must not add columns")
+	}
+	if lv.currentBgColor != tcell.ColorLightGray {
+		t.Errorf("Unexpected current background color: %v", lv.currentBgColor)
+	}
+	if lv.selectionBgColor != tcell.ColorLightSteelBlue || lv.bookmarkColor != tcell.ColorTeal {
+		t.Errorf("Unexpected selection background or bookmark color: %v %v", lv.selectionBgColor, lv.bookmarkColor)
+	}
+	if _, bg = lv.GetLevelColors(LogLevelWarning); bg != tcell.ColorMoccasin {
+		t.Errorf("Unexpected warning background color: %v", bg)
+	}
+
+	// events that were already in the log view are recolorized
+	if _, bg, _ = lv.firstEvent.styleSpans[0].style.Decompose(); bg != tcell.ColorWhite {
+		t.Errorf("Existing event was not recolorized, background %v", bg)
+	}
+	if _, bg, _ = lv.lastEvent.styleSpans[0].style.Decompose(); bg != tcell.ColorMoccasin {
+		t.Errorf("Existing warning was not recolorized, background %v", bg)
+	}
+
+	// styles not set in the theme are kept
+	lv.ApplyTheme(&Theme{Search: "white_red"})
+	if fg, _, _ = lv.defaultStyle.Decompose(); fg != tcell.ColorBlack {
+		t.Errorf("Text style must not change")
+	}
+	if fg, bg, _ = lv.searchStyle.Decompose(); fg != tcell.ColorWhite || bg != tcell.ColorRed {
+		t.Errorf("Unexpected search colors: %v %v", fg, bg)
+	}
+}
+
+func TestLogVelocityView_ApplyTheme(t *testing.T) {
+	lh := NewLogVelocityView(time.Second)
+	lh.ApplyTheme(NewHighContrastTheme())
+
+	if fg, bg, _ := lh.defaultStyle.Decompose(); fg != tcell.ColorWhite || bg != tcell.ColorBlack {
+		t.Errorf("Unexpected text colors: %v %v", fg, bg)
+	}
+	if lh.levelColors[LogLevelError] != tcell.ColorRed {
+		t.Errorf("Unexpected error color: %v", lh.levelColors[LogLevelError])
+	}
+}
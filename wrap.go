@@ -0,0 +1,140 @@
+package logview
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"strings"
+	"unicode"
+)
+
+// WrapMode defines where long lines are broken when line wrapping is enabled
+type WrapMode int
+
+const (
+	// WrapCharacter breaks lines exactly at the page width
+	WrapCharacter WrapMode = iota
+	// WrapWord breaks lines after whitespace or, if there is none, after punctuation. Words longer than the page width are broken at the
+	// page width
+	WrapWord
+)
+
+// wordBreaks are the punctuation characters after which the line can be broken in WrapWord mode
+const wordBreaks = ",.;:!?-/\\|&=)]}>"
+
+// SetWrapMode sets where long lines are broken when line wrapping is enabled. Default is WrapCharacter
+func (lv *LogView) SetWrapMode(mode WrapMode) {
+	lv.Lock()
+	defer lv.Unlock()
+
+	if lv.wrapMode != mode {
+		lv.forceWrap = true
+	}
+	lv.wrapMode = mode
+}
+
+// GetWrapMode returns the current wrap mode
+func (lv *LogView) GetWrapMode() WrapMode {
+	lv.RLock()
+	defer lv.RUnlock()
+
+	return lv.wrapMode
+}
+
+// SetWrapIndent sets hanging indent for the continuation lines of wrapped events. Lines that start after a new line
+// character in the event message are not indented.
+//
+// If marker is not 0, it is displayed in the first character of the indent, i.e. SetWrapIndent(2, '↪').
+// Default is no indent and no marker
+func (lv *LogView) SetWrapIndent(indent int, marker rune) {
+	lv.Lock()
+	defer lv.Unlock()
+
+	if indent < 0 {
+		indent = 0
+	}
+	if lv.wrapIndent != indent {
+		lv.forceWrap = true
+	}
+	lv.wrapIndent = indent
+	lv.wrapMarker = marker
+}
+
+// GetWrapIndent returns the indent and the marker of continuation lines
+func (lv *LogView) GetWrapIndent() (int, rune) {
+	lv.RLock()
+	defer lv.RUnlock()
+
+	return lv.wrapIndent, lv.wrapMarker
+}
+
+// *******************************
+// internal implementation details
+
+// findLineEnd finds where the line starting at start position should be broken to fit into width.
+// Width is measured in screen cells.
+// Returns the position after the last character of the line and whether the line ends with the new line character
+func (lv *LogView) findLineEnd(runes []rune, start int, width int) (int, bool) {
+	end, used := start, 0
+	for end < len(runes) {
+		if runes[end] == '\n' {
+			return end + 1, true
+		}
+		w := cellWidth(runes[end])
+		if used+w > width && end > start {
+			break
+		}
+		used += w
+		end++
+	}
+	if end == len(runes) {
+		return end, false
+	}
+	if runes[end] == '\n' {
+		return end + 1, true
+	}
+	if lv.wrapMode == WrapWord {
+		if unicode.IsSpace(runes[end]) {
+			// trailing whitespace stays on the line even if it doesn't fit, it is not visible anyway
+			for end < len(runes) && runes[end] != '\n' && unicode.IsSpace(runes[end]) {
+				end++
+			}
+			if end < len(runes) && runes[end] == '\n' {
+				return end + 1, true
+			}
+			return end, false
+		}
+		// prefer breaking at whitespace, then at punctuation
+		for pos := end - 1; pos > start; pos-- {
+			if unicode.IsSpace(runes[pos]) {
+				return pos + 1, false
+			}
+		}
+		for pos := end - 1; pos > start; pos-- {
+			if strings.ContainsRune(wordBreaks, runes[pos]) {
+				return pos + 1, false
+			}
+		}
+	}
+	return end, false
+}
+
+// drawWrapIndent draws the indent of continuation line and returns its width
+func (lv *LogView) drawWrapIndent(screen tcell.Screen, x int, y int, event *logEventLine) int {
+	style := lv.defaultStyle
+	if bg, highlighted := lv.lineBackground(event); highlighted {
+		style = style.Background(bg)
+	}
+	indent := lv.wrapIndentWidth()
+	for i := 0; i < indent; i++ {
+		r := ' '
+		if i == 0 && lv.wrapMarker != 0 {
+			r = lv.wrapMarker
+		}
+		screen.SetCell(x+i, y, style, r)
+	}
+	return indent
+}
+
+// wrapIndentWidth returns the hanging indent reduced to leave at least one cell for the text of continuation line
+func (lv *LogView) wrapIndentWidth() int {
+	return minInt(lv.wrapIndent, maxInt(lv.pageWidth-1, 0))
+}
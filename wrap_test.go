@@ -0,0 +1,123 @@
+package logview
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"strings"
+	"testing"
+)
+
+func wrappedLines(lv *LogView) []string {
+	var lines []string
+	for e := lv.firstEvent; e != nil; e = e.next {
+		lines = append(lines, string(e.Runes[e.start:e.end]))
+	}
+	return lines
+}
+
+func TestLogView_WrapWord(t *testing.T) {
+	lv := NewLogView()
+	lv.pageWidth = 20
+	lv.SetWrapMode(WrapWord)
+	//                                 1        10        20        30        40
+	//                                 |        |         |         |         |
+	lv.AppendEvent(NewLogEvent("1", "Connection to http://example.com/api/v1/users failed"))
+
+	lines := wrappedLines(lv)
+	expected := []string{"Connection to ", "http://example.com/", "api/v1/users failed"}
+	if len(lines) != len(expected) {
+		t.Fatalf("Invalid wrapped lines: %q", lines)
+	}
+	for i := range expected {
+		if lines[i] != expected[i] {
+			t.Errorf("Invalid wrapped line %d: %q, expected %q", i, lines[i], expected[i])
+		}
+	}
+	if lv.firstEvent.lineCount != 3 || lv.lastEvent.order != 3 || !lv.lastEvent.continued || lv.firstEvent.continued {
+		t.Errorf("Invalid line count or order")
+	}
+
+	lv.Clear()
+	lv.AppendEvent(NewLogEvent("2", "abcdefghijklmnopqrstuvwxyz"))
+	if lines := wrappedLines(lv); len(lines) != 2 || lines[0] != "abcdefghijklmnopqrst" {
+		t.Errorf("Long word should be broken at page width: %q", lines)
+	}
+}
+
+func TestLogView_WrapIndent(t *testing.T) {
+	screen := tcell.NewSimulationScreen("UTF-8")
+	screen.Init()
+	screen.SetSize(12, 5)
+	lv := NewLogView()
+	lv.SetRect(0, 0, 12, 5)
+	lv.SetWrapIndent(2, '↪')
+	lv.AppendEvent(NewLogEvent("1", "0123456789abcdefghijklmnop\nqrs"))
+	lv.Draw(screen)
+	screen.Show()
+
+	expected := []string{"0123456789ab", "↪ cdefghijkl", "↪ mnop", "qrs"}
+	for i, line := range expected {
+		if screenLine(screen, i) != line {
+			t.Errorf("Invalid line %d: '%s', expected '%s'", i, screenLine(screen, i), line)
+		}
+	}
+	if lv.firstEvent.lineCount != 4 {
+		t.Errorf("Invalid line count: %d", lv.firstEvent.lineCount)
+	}
+
+	lv.SetWrapIndent(0, 0)
+	lv.Draw(screen)
+	screen.Show()
+	if screenLine(screen, 1) != "cdefghijklmn" {
+		t.Errorf("Changing indent should rewrap events, second line: '%s'", screenLine(screen, 1))
+	}
+	// indent wider than the page leaves one cell for the text
+	lv.SetWrapIndent(20, '↪')
+	lv.Draw(screen)
+	lv.ScrollToTop()
+	lv.Draw(screen)
+	screen.Show()
+	for i, line := range []string{"0123456789ab", "↪          c", "↪          d"} {
+		if screenLine(screen, i) != line {
+			t.Errorf("Invalid line %d: '%s', expected '%s'", i, screenLine(screen, i), line)
+		}
+	}
+}
+
+func TestLogView_WrapWideCharacters(t *testing.T) {
+	lv := NewLogView()
+	lv.pageWidth = 10
+	lv.AppendEvent(NewLogEvent("1", "日本語のテキストです"))
+
+	lines := wrappedLines(lv)
+	if len(lines) != 2 || lines[0] != "日本語のテ" || lines[1] != "キストです" {
+		t.Errorf("Wide characters should take two cells: %q", lines)
+	}
+
+	lv.Clear()
+	lv.AppendEvent(NewLogEvent("2", strings.Repeat("e\u0301", 10)))
+	if lv.firstEvent.lineCount != 1 {
+		t.Errorf("Combining characters should not take cells: %q", wrappedLines(lv))
+	}
+}
+
+func TestLogView_DrawWideCharacters(t *testing.T) {
+	screen := tcell.NewSimulationScreen("UTF-8")
+	screen.Init()
+	screen.SetSize(20, 3)
+	lv := NewLogView()
+	lv.SetRect(0, 0, 20, 3)
+	lv.SetShowSource(true)
+	lv.SetSourceClipLength(5)
+	lv.AppendEvent(&LogEvent{EventID: "1", Source: "日本語", Message: "e\u0301日本"})
+	lv.Draw(screen)
+	screen.Show()
+
+	cells, _, _ := screen.GetContents()
+	// header is 5 cells of source + " | ", "日本語" doesn't fit and is clipped to "日本" padded to 5 cells
+	if string(cells[1].Runes) != "日" || string(cells[3].Runes) != "本" || string(cells[6].Runes) != "|" {
+		t.Errorf("Invalid header cells: %q %q %q", cells[1].Runes, cells[3].Runes, cells[6].Runes)
+	}
+	if string(cells[8].Runes) != "e\u0301" || string(cells[9].Runes) != "日" || string(cells[11].Runes) != "本" {
+		t.Errorf("Invalid message cells: %q %q %q", cells[8].Runes, cells[9].Runes, cells[11].Runes)
+	}
+}